@@ -5,11 +5,12 @@ import (
     "os"
 
     "github.com/nareshku/kubectl-event-summary/pkg/cmd"
+    "github.com/nareshku/kubectl-event-summary/pkg/events"
 )
 
 func main() {
     command := cmd.NewEventSummaryCommand()
     if err := command.Execute(); err != nil {
-        os.Exit(1)
+        os.Exit(events.ExitCode(err))
     }
 }
\ No newline at end of file
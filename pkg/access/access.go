@@ -0,0 +1,183 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Checks lists the permissions the plugin relies on
+var Checks = []Check{
+	{Resource: "events", Verb: "list", Required: true, Feature: "summarizing events"},
+	{Resource: "events", Verb: "watch", Feature: "watching for new events"},
+	{Group: "events.k8s.io", Resource: "events", Verb: "list", Feature: "reading events through the events.k8s.io API"},
+	{Group: "events.k8s.io", Resource: "events", Verb: "watch", Feature: "watching events through the events.k8s.io API"},
+	{Resource: "events", Verb: "list", ClusterWide: true, Feature: "listing events cluster-wide with -A (falls back to per-namespace listing)"},
+	{Resource: "namespaces", Verb: "list", ClusterWide: true, Feature: "per-namespace fallback when -A is forbidden (use --namespaces instead)"},
+	{Resource: "pods", Verb: "list", Feature: "--recursive owner resolution of Pods"},
+	{Group: "apps", Resource: "replicasets", Verb: "list", Feature: "--recursive owner resolution of ReplicaSets"},
+	{Group: "batch", Resource: "jobs", Verb: "list", Feature: "--recursive owner resolution of Jobs"},
+	{Resource: "persistentvolumeclaims", Verb: "list", Feature: "--recursive owner resolution of PVCs"},
+	{Resource: "pods", Verb: "get", Feature: "--problems workload attribution of Pods"},
+	{Group: "apps", Resource: "replicasets", Verb: "get", Feature: "--problems workload attribution of Deployment Pods"},
+	{Group: "batch", Resource: "jobs", Verb: "get", Feature: "--problems workload attribution of CronJob Pods"},
+	{Resource: "nodes", Verb: "get", ClusterWide: true, Feature: "summarizing events of node/NAME"},
+}
+
+// Complete completes all the required options
+func (o *CheckAccessOptions) Complete(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the provided options
+func (o *CheckAccessOptions) Validate() error {
+	return nil
+}
+
+// Run reviews every permission in Checks and prints what is missing
+func (o *CheckAccessOptions) Run() error {
+	config, err := o.ConfigFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get client config: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %v", err)
+	}
+
+	var namespace string
+	if !o.AllNs {
+		namespace, _, err = o.ConfigFlags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return fmt.Errorf("failed to get namespace: %v", err)
+		}
+	}
+
+	results, err := Review(context.TODO(), clientset, namespace)
+	if err != nil {
+		return err
+	}
+
+	PrintTable(o.Out, results)
+	return checkRequired(results, namespace)
+}
+
+// checkRequired returns an error if a permission the plugin can't do
+// without was denied in the namespace, or across all namespaces if empty
+func checkRequired(results []Result, namespace string) error {
+	for _, result := range results {
+		if result.Required && !result.Allowed {
+			return fmt.Errorf("missing permissions required for %s", result.Feature)
+		}
+	}
+	// With -A, events are listed cluster-wide or, failing that, namespace
+	// by namespace; without either there is nothing to summarize
+	if namespace == "" && !allowed(results, "events", "list") && !allowed(results, "namespaces", "list") {
+		return fmt.Errorf("missing permissions required for summarizing events: neither events nor namespaces can be listed cluster-wide")
+	}
+	return nil
+}
+
+// allowed reports whether the core permission was granted in the results
+func allowed(results []Result, resource, verb string) bool {
+	for _, result := range results {
+		if result.Group == "" && result.Resource == resource && result.Verb == verb && result.Allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Review runs a SelfSubjectAccessReview for every check in the given
+// namespace, or across all namespaces when namespace is empty
+func Review(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]Result, error) {
+	clusterWide := make(map[authorizationv1.ResourceAttributes]bool)
+	for _, check := range Checks {
+		if check.ClusterWide {
+			clusterWide[authorizationv1.ResourceAttributes{Group: check.Group, Resource: check.Resource, Verb: check.Verb}] = true
+		}
+	}
+
+	var results []Result
+	seen := make(map[authorizationv1.ResourceAttributes]bool)
+	for _, check := range Checks {
+		attrs := authorizationv1.ResourceAttributes{
+			Group:     check.Group,
+			Resource:  check.Resource,
+			Verb:      check.Verb,
+			Namespace: namespace,
+		}
+		if check.ClusterWide {
+			attrs.Namespace = ""
+		}
+		// With -A the namespaced and cluster-wide checks coincide, and the
+		// cluster-wide one tells how the plugin copes without it: listing
+		// events cluster-wide is optional, as it falls back to listing them
+		// namespace by namespace
+		if seen[attrs] || (namespace == "" && !check.ClusterWide && clusterWide[attrs]) {
+			continue
+		}
+		seen[attrs] = true
+
+		review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review access to %s %s: %v", check.Verb, check.Resource, err)
+		}
+
+		results = append(results, Result{
+			Check:     check,
+			Namespace: attrs.Namespace,
+			Allowed:   review.Status.Allowed,
+			Reason:    review.Status.Reason,
+		})
+	}
+	return results, nil
+}
+
+// PrintTable prints the review results and the features that degrade
+// because of missing permissions
+func PrintTable(out io.Writer, results []Result) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "API GROUP\tRESOURCE\tVERB\tNAMESPACE\tALLOWED\tDEGRADED FEATURE")
+	missing := 0
+	for _, result := range results {
+		group := result.Group
+		if group == "" {
+			group = "core"
+		}
+		namespace := result.Namespace
+		if namespace == "" {
+			namespace = "(all)"
+		}
+		allowed := "yes"
+		feature := "-"
+		if !result.Allowed {
+			missing++
+			allowed = "no"
+			feature = result.Feature
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", group, result.Resource, result.Verb, namespace, allowed, feature)
+	}
+	w.Flush()
+
+	if missing == 0 {
+		fmt.Fprintln(out, "\nAll permissions are granted")
+		return
+	}
+	fmt.Fprintf(out, "\nMissing permissions: %d of %d\n", missing, len(results))
+	for _, result := range results {
+		if result.Allowed || result.Reason == "" {
+			continue
+		}
+		fmt.Fprintf(out, "  %s %s: %s\n", result.Verb, result.Resource, result.Reason)
+	}
+}
@@ -0,0 +1,109 @@
+package access
+
+import (
+	"context"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeReviews answers access reviews from the granted "verb resource"
+// permissions, cluster-wide ones with an empty namespace
+func fakeReviews(granted map[string]bool) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		key := attrs.Verb + " " + attrs.Resource
+		if attrs.Namespace != "" {
+			key += " in " + attrs.Namespace
+		}
+		review.Status.Allowed = granted[key]
+		return true, review, nil
+	})
+	return clientset
+}
+
+func TestReviewAllNamespaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted map[string]bool
+		wantErr string
+	}{
+		{
+			name:    "cluster-wide events",
+			granted: map[string]bool{"list events": true},
+		},
+		{
+			name:    "per-namespace fallback",
+			granted: map[string]bool{"list namespaces": true},
+		},
+		{
+			name:    "neither",
+			granted: map[string]bool{"list pods": true},
+			wantErr: "missing permissions required for summarizing events: neither events nor namespaces can be listed cluster-wide",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Review(context.Background(), fakeReviews(tt.granted), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			eventsList := 0
+			for _, result := range results {
+				if result.Namespace != "" {
+					t.Errorf("%s %s: reviewed in namespace %q", result.Verb, result.Resource, result.Namespace)
+				}
+				if result.Group == "" && result.Resource == "events" && result.Verb == "list" {
+					eventsList++
+					if result.Required || !result.ClusterWide {
+						t.Errorf("cluster-wide list events: got required=%v, clusterWide=%v", result.Required, result.ClusterWide)
+					}
+				}
+			}
+			if eventsList != 1 {
+				t.Errorf("got %d reviews of list events, want 1", eventsList)
+			}
+
+			err = checkRequired(results, "")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReviewNamespace(t *testing.T) {
+	results, err := Review(context.Background(), fakeReviews(map[string]bool{"list namespaces": true}), "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var required []Result
+	for _, result := range results {
+		if result.Required {
+			required = append(required, result)
+		}
+	}
+	if len(required) != 1 || required[0].Resource != "events" || required[0].Namespace != "shop" {
+		t.Fatalf("got required checks %+v, want list events in shop", required)
+	}
+	if err := checkRequired(results, "shop"); err == nil || err.Error() != "missing permissions required for summarizing events" {
+		t.Errorf("got error %v, want events to be required in the namespace", err)
+	}
+
+	results, err = Review(context.Background(), fakeReviews(map[string]bool{"list events in shop": true}), "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkRequired(results, "shop"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
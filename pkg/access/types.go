@@ -0,0 +1,42 @@
+package access
+
+import (
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// CheckAccessOptions contains the options for the check-access command
+type CheckAccessOptions struct {
+	ConfigFlags *genericclioptions.ConfigFlags
+	AllNs       bool
+
+	genericclioptions.IOStreams
+}
+
+// NewCheckAccessOptions returns initialized CheckAccessOptions
+func NewCheckAccessOptions(streams genericclioptions.IOStreams) *CheckAccessOptions {
+	return &CheckAccessOptions{
+		ConfigFlags: genericclioptions.NewConfigFlags(true),
+		IOStreams:   streams,
+	}
+}
+
+// Check is a permission the plugin relies on
+type Check struct {
+	Group    string
+	Resource string
+	Verb     string
+	// ClusterWide checks the permission across all namespaces
+	ClusterWide bool
+	// Required checks fail the command when denied
+	Required bool
+	// Feature describes what degrades when the permission is missing
+	Feature string
+}
+
+// Result is the outcome of a single access review
+type Result struct {
+	Check
+	Namespace string
+	Allowed   bool
+	Reason    string
+}
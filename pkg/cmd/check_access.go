@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/access"
+)
+
+// NewCheckAccessCommand creates the check-access command
+func NewCheckAccessCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := access.NewCheckAccessOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "check-access [flags]",
+		Short:        "Check the RBAC permissions needed to summarize events",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	AddCheckAccessFlags(cmd, o)
+	return cmd
+}
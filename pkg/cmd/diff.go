@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/events"
+)
+
+// NewDiffCommand creates the diff command
+func NewDiffCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := events.NewDiffOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "diff [resource] [name] [flags]",
+		Short:        "Compare the event groups of two time windows or snapshots",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	AddDiffFlags(cmd, o)
+	return cmd
+}
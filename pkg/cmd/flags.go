@@ -4,7 +4,10 @@ import (
 	"github.com/spf13/cobra"
 	"time"
 	
+	"github.com/nareshku/kubectl-event-summary/pkg/access"
 	"github.com/nareshku/kubectl-event-summary/pkg/events"
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/server"
 	"github.com/nareshku/kubectl-event-summary/pkg/types"
 )
 
@@ -13,13 +16,165 @@ func AddFlags(cmd *cobra.Command, o *events.EventSummaryOptions) {
 	o.ConfigFlags.AddFlags(cmd.Flags())
 	cmd.Flags().BoolVarP(&o.AllNs, "all-namespaces", "A", false, "If present, summarize events across all namespaces")
 	cmd.Flags().StringVar(&o.SortBy, "sort-by", "lastTimestamp", "Sort events by (lastTimestamp, count)")
-	cmd.Flags().StringVarP(&o.Format, "output", "o", "wide", "Output format. One of: wide|json|yaml")
+	cmd.Flags().StringVarP(&o.Format, "output", "o", "wide", "Output format. One of: wide|json|yaml|table|custom-columns=SPEC|go-template=...|go-template-file=...|jsonpath=...|jsonpath-file=...|csv|tsv|markdown|html|prometheus|openmetrics|ndjson|junit|github-annotations|gitlab-codequality|otlp-json|loki-push|es-bulk|cloudevents")
 	cmd.Flags().DurationVar(&o.Since, "since", 15*time.Minute, "Show events from the last duration (e.g., 5m, 1h)")
-	cmd.Flags().StringVar(&o.GroupBy, "group-by", "", "Group events by (comma-separated): kind,namespace,reason,type")
+	cmd.Flags().StringVar(&o.GroupBy, "group-by", "", "Group events by (comma-separated): kind,namespace,reason,type,cluster")
 	cmd.Flags().BoolVar(&o.Compact, "compact", false, "Show only group summaries")
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", false, "When using table, custom-columns, csv or tsv output, don't print headers")
 	cmd.Flags().StringVar(&o.Filter, "filter", "", "Filter groups by prefix (e.g., 'kind=Pod')")
 	cmd.Flags().StringVar((*string)(&o.Severity), "severity", string(types.SeverityAll),
 		"Filter events by severity (all|normal|warning|error)")
 	cmd.Flags().StringVar(&o.Search, "search", "", 
 		"Search string to filter events (searches in name, message, reason, and namespace)")
-} 
\ No newline at end of file
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "",
+		"Selector (label query) to filter the resources whose events are summarized (e.g. -l app=web)")
+	cmd.Flags().BoolVar(&o.Recursive, "recursive", false,
+		"Also summarize events of objects owned by the given resources (ReplicaSets, Pods, PVCs)")
+	cmd.Flags().StringSliceVar(&o.Contexts, "contexts", nil,
+		"Comma-separated kubeconfig contexts to summarize events from in parallel")
+	cmd.Flags().BoolVar(&o.AllContexts, "all-contexts", false,
+		"If present, summarize events from every context in the kubeconfig")
+	cmd.Flags().DurationVar(&o.ClusterTimeout, "cluster-timeout", 30*time.Second,
+		"Maximum time to wait for each cluster when using --contexts or --all-contexts")
+	cmd.Flags().StringSliceVar(&o.Namespaces, "namespaces", nil,
+		"Comma-separated namespaces to summarize events from, listed one namespace at a time")
+	cmd.Flags().IntVar(&o.MaxConcurrency, "max-concurrency", 5,
+		"Maximum number of parallel requests when listing events per namespace")
+	cmd.Flags().StringArrayVar(&o.FailOn, "fail-on", nil,
+		"Exit with code 2 when a threshold is breached (e.g. warnings>0, errors>=3, reason=FailedScheduling); may be repeated")
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", false,
+		"Repeat the summary every --watch-interval until interrupted; ndjson output only writes new events")
+	cmd.Flags().DurationVar(&o.WatchInterval, "watch-interval", 30*time.Second, "Interval between summaries in watch mode")
+	cmd.Flags().StringVar(&o.Notify.URL, "notify-webhook", "", "Post the summary to this webhook URL")
+	cmd.Flags().StringVar(&o.Notify.Format, "notify-format", "slack", "Webhook payload format. One of: slack|teams|generic")
+	cmd.Flags().StringVar(&o.Notify.TemplateFile, "notify-template", "",
+		"Go template file rendering the webhook body instead of --notify-format (fields: .Title, .Summary, .Groups)")
+	cmd.Flags().StringVar(&o.Notify.ObjectURL, "notify-object-url", "",
+		"Go template linking objects in the webhook message (e.g. 'https://dashboard/{{.Namespace}}/{{.Kind}}/{{.Name}}')")
+	cmd.Flags().BoolVar(&o.Notify.DryRun, "notify-dry-run", false, "Print the webhook payload instead of posting it")
+	cmd.Flags().IntVar(&o.Notify.Retries, "notify-retries", 3, "Number of retries, with exponential backoff, when posting to a webhook, Alertmanager or an export endpoint fails")
+	cmd.Flags().DurationVar(&o.Notify.Timeout, "notify-timeout", 10*time.Second, "Timeout of each webhook, Alertmanager and export request")
+	cmd.Flags().StringVar(&o.AlertmanagerURL, "alertmanager-url", "",
+		"Send an alert for each group breaching an --alert-on rule to this Alertmanager (e.g. http://alertmanager:9093)")
+	cmd.Flags().StringArrayVar(&o.AlertOn, "alert-on", []string{"warnings>0"},
+		"Alert on groups breaching this rule, in --fail-on syntax (e.g. errors>=3, reason=BackOff); may be repeated")
+	cmd.Flags().StringArrayVar(&o.AlertLabels, "alert-label", nil, "Extra label (name=value) added to each alert; may be repeated")
+	cmd.Flags().StringVar(&o.OTLP.URL, "otlp-endpoint", "",
+		"Export the filtered events as OTLP log records to this collector (e.g. http://otel-collector:4318)")
+	cmd.Flags().StringVar(&o.OTLP.Protocol, "otlp-protocol", "http/protobuf", "OTLP protocol. One of: http/protobuf|http/json")
+	cmd.Flags().StringArrayVar(&o.OTLP.Headers, "otlp-header", nil, "Header (name=value) added to OTLP requests; may be repeated")
+	cmd.Flags().StringVar(&o.Loki.URL, "loki-url", "", "Push the filtered events to this Loki (e.g. http://loki:3100)")
+	cmd.Flags().StringArrayVar(&o.Loki.Headers, "loki-header", nil,
+		"Header (name=value) added to Loki requests (e.g. X-Scope-OrgID=tenant); may be repeated")
+	cmd.Flags().StringVar(&o.Elasticsearch.URL, "es-url", "", "Index the filtered events into this Elasticsearch (e.g. http://elasticsearch:9200)")
+	cmd.Flags().StringArrayVar(&o.Elasticsearch.Headers, "es-header", nil,
+		"Header (name=value) added to Elasticsearch requests (e.g. 'Authorization=ApiKey ...'); may be repeated")
+	cmd.Flags().StringVar(&o.CloudEvents.URL, "cloudevents-url", "",
+		"Send each filtered event and the summary as a binary-mode CloudEvent to this URL")
+	cmd.Flags().StringArrayVar(&o.CloudEvents.Headers, "cloudevents-header", nil,
+		"Header (name=value) added to CloudEvents requests; may be repeated")
+	cmd.Flags().StringVar(&o.ESIndex, "es-index", output.DefaultESIndex, "Elasticsearch index of es-bulk output and --es-url")
+	cmd.Flags().StringVar(&o.FromSnapshot, "from-snapshot", "",
+		"Summarize the events of a snapshot written by the record command instead of fetching them")
+	cmd.Flags().BoolVar(&o.Problems, "problems", false,
+		"Report known failure patterns (crash loops, OOM kills, image pulls, scheduling, volumes, probes, evictions) per workload")
+	cmd.Flags().BoolVar(&o.Anomalies, "anomalies", false,
+		"Rank groups by how far their count deviates from the same-length windows before the summary window")
+	cmd.Flags().IntVar(&o.BaselineWindows, "baseline-windows", 3, "Number of --since windows the anomaly baseline spans")
+	cmd.Flags().StringVar(&o.BaselineSnapshot, "baseline-snapshot", "",
+		"Take the anomaly baseline from a snapshot written by the record command, ending when it was recorded")
+	cmd.Flags().Float64Var(&o.AnomalyThreshold, "anomaly-threshold", 2,
+		"Minimum absolute z-score of a group listed as an anomaly; 0 lists every group")
+	addStoreFlags(cmd, o)
+} 
+// AddCheckAccessFlags adds flags to the check-access command.
+func AddCheckAccessFlags(cmd *cobra.Command, o *access.CheckAccessOptions) {
+	o.ConfigFlags.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVarP(&o.AllNs, "all-namespaces", "A", false, "If present, check permissions across all namespaces")
+}
+
+// AddServeFlags adds flags to the serve command.
+func AddServeFlags(cmd *cobra.Command, o *server.ServeOptions) {
+	s := o.Summary
+	s.ConfigFlags.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVarP(&s.AllNs, "all-namespaces", "A", false, "If present, watch events across all namespaces")
+	cmd.Flags().StringVar(&o.Listen, "listen", ":9102", "Address to serve /metrics, /healthz and /summary on")
+	cmd.Flags().DurationVar(&s.Since, "since", 15*time.Minute, "Default summary window (e.g., 5m, 1h)")
+	cmd.Flags().StringVar(&s.GroupBy, "group-by", "namespace,kind,reason,type",
+		"Default grouping (comma-separated): kind,namespace,reason,type")
+	cmd.Flags().StringVar(&s.Filter, "filter", "", "Filter groups by prefix (e.g., 'kind=Pod')")
+	cmd.Flags().StringVar((*string)(&s.Severity), "severity", string(types.SeverityAll),
+		"Filter events by severity (all|normal|warning|error)")
+	cmd.Flags().StringVar(&s.Search, "search", "",
+		"Search string to filter events (searches in name, message, reason, and namespace)")
+	addStoreFlags(cmd, s)
+}
+
+// addStoreFlags adds the local event store flags shared by the summary and
+// serve commands.
+func addStoreFlags(cmd *cobra.Command, o *events.EventSummaryOptions) {
+	cmd.Flags().StringVar(&o.Store.Dir, "store-dir", "",
+		"Directory of a local event store that --watch and serve append to, read when --since exceeds --event-ttl")
+	cmd.Flags().DurationVar(&o.Store.Retention, "store-retention", 7*24*time.Hour, "How long the local event store keeps events")
+	cmd.Flags().StringVar(&o.Store.MaxSize, "store-max-size", "",
+		"Maximum size of the local event store (e.g. 500Mi); the oldest events are removed first")
+	cmd.Flags().DurationVar(&o.EventTTL, "event-ttl", time.Hour,
+		"How long the API server keeps events (its --event-ttl); longer windows also read the local event store")
+}
+
+// AddRecordFlags adds flags to the record command.
+func AddRecordFlags(cmd *cobra.Command, o *events.RecordOptions) {
+	s := o.Summary
+	s.ConfigFlags.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVarP(&s.AllNs, "all-namespaces", "A", false, "If present, record events across all namespaces")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Snapshot file to write, gzipped if it ends in .gz (e.g. snapshot.json.gz)")
+	cmd.Flags().StringVarP(&s.Selector, "selector", "l", "",
+		"Selector (label query) to filter the resources whose events are recorded (e.g. -l app=web)")
+	cmd.Flags().BoolVar(&s.Recursive, "recursive", false,
+		"Also record events of objects owned by the given resources (ReplicaSets, Pods, PVCs)")
+	cmd.Flags().StringSliceVar(&s.Contexts, "contexts", nil,
+		"Comma-separated kubeconfig contexts to record events from in parallel")
+	cmd.Flags().BoolVar(&s.AllContexts, "all-contexts", false,
+		"If present, record events from every context in the kubeconfig")
+	cmd.Flags().DurationVar(&s.ClusterTimeout, "cluster-timeout", 30*time.Second,
+		"Maximum time to wait for each cluster when using --contexts or --all-contexts")
+	cmd.Flags().StringSliceVar(&s.Namespaces, "namespaces", nil,
+		"Comma-separated namespaces to record events from, listed one namespace at a time")
+	cmd.Flags().IntVar(&s.MaxConcurrency, "max-concurrency", 5,
+		"Maximum number of parallel requests when listing events per namespace")
+}
+
+// AddDiffFlags adds flags to the diff command.
+func AddDiffFlags(cmd *cobra.Command, o *events.DiffOptions) {
+	s := o.Summary
+	s.ConfigFlags.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVarP(&s.AllNs, "all-namespaces", "A", false, "If present, compare events across all namespaces")
+	cmd.Flags().StringVarP(&o.Format, "output", "o", "wide", "Output format. One of: wide|json|yaml")
+	cmd.Flags().DurationVar(&o.Before, "before", time.Hour, "Length of the before window, which ends where the after window starts")
+	cmd.Flags().DurationVar(&o.After, "after", 15*time.Minute, "Length of the after window, which ends now")
+	cmd.Flags().StringVar(&o.BeforeSnapshot, "before-snapshot", "",
+		"Take the before window from a snapshot written by the record command, ending when it was recorded")
+	cmd.Flags().StringVar(&o.AfterSnapshot, "after-snapshot", "",
+		"Take the after window from a snapshot written by the record command, ending when it was recorded")
+	cmd.Flags().StringVar(&s.GroupBy, "group-by", "namespace,reason",
+		"Group events by (comma-separated): kind,namespace,reason,type,cluster")
+	cmd.Flags().StringVar(&s.Filter, "filter", "", "Filter groups by prefix (e.g., 'kind=Pod')")
+	cmd.Flags().StringVar((*string)(&s.Severity), "severity", string(types.SeverityAll),
+		"Filter events by severity (all|normal|warning|error)")
+	cmd.Flags().StringVar(&s.Search, "search", "",
+		"Search string to filter events (searches in name, message, reason, and namespace)")
+	cmd.Flags().StringVarP(&s.Selector, "selector", "l", "",
+		"Selector (label query) to filter the resources whose events are compared (e.g. -l app=web)")
+	cmd.Flags().BoolVar(&s.Recursive, "recursive", false,
+		"Also compare events of objects owned by the given resources (ReplicaSets, Pods, PVCs)")
+	cmd.Flags().StringSliceVar(&s.Contexts, "contexts", nil,
+		"Comma-separated kubeconfig contexts to compare events from in parallel")
+	cmd.Flags().BoolVar(&s.AllContexts, "all-contexts", false,
+		"If present, compare events from every context in the kubeconfig")
+	cmd.Flags().DurationVar(&s.ClusterTimeout, "cluster-timeout", 30*time.Second,
+		"Maximum time to wait for each cluster when using --contexts or --all-contexts")
+	cmd.Flags().StringSliceVar(&s.Namespaces, "namespaces", nil,
+		"Comma-separated namespaces to compare events from, listed one namespace at a time")
+	cmd.Flags().IntVar(&s.MaxConcurrency, "max-concurrency", 5,
+		"Maximum number of parallel requests when listing events per namespace")
+}
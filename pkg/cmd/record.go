@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/events"
+)
+
+// NewRecordCommand creates the record command
+func NewRecordCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := events.NewRecordOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "record [resource] [name] -o FILE [flags]",
+		Short:        "Record the raw events to a snapshot file for replay with --from-snapshot",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	AddRecordFlags(cmd, o)
+	return cmd
+}
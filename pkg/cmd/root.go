@@ -39,16 +39,19 @@ func NewEventSummaryOptions(streams genericclioptions.IOStreams) *EventSummaryOp
 
 // NewEventSummaryCommand creates the event-summary command
 func NewEventSummaryCommand() *cobra.Command {
-    o := events.NewEventSummaryOptions(genericclioptions.IOStreams{
+    streams := genericclioptions.IOStreams{
         In:     os.Stdin,
         Out:    os.Stdout,
         ErrOut: os.Stderr,
-    })
+    }
+    o := events.NewEventSummaryOptions(streams)
 
     cmd := &cobra.Command{
-        Use:          "event-summary [flags]",
+        Use:          "event-summary [TYPE/NAME ...] [flags]",
         Short:        "Summarize Kubernetes events",
         SilenceUsage: true,
+        // Positional arguments are resource references, not subcommands
+        Args:         cobra.ArbitraryArgs,
         RunE: func(c *cobra.Command, args []string) error {
             if err := o.Complete(c, args); err != nil {
                 return err
@@ -64,6 +67,10 @@ func NewEventSummaryCommand() *cobra.Command {
     }
 
     AddFlags(cmd, o)
+    cmd.AddCommand(NewCheckAccessCommand(streams))
+    cmd.AddCommand(NewServeCommand(streams))
+    cmd.AddCommand(NewRecordCommand(streams))
+    cmd.AddCommand(NewDiffCommand(streams))
     return cmd
 }
 
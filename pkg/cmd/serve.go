@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/server"
+)
+
+// NewServeCommand creates the serve command
+func NewServeCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := server.NewServeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "serve [flags]",
+		Short:        "Serve event summaries as Prometheus metrics and JSON",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	AddServeFlags(cmd, o)
+	return cmd
+}
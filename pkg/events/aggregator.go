@@ -0,0 +1,271 @@
+package events
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// Aggregator keeps the groups of a changing set of events, such as those of
+// an informer, up to date one event at a time, so that their summary can be
+// served without filtering and grouping every event again. Events leave
+// their group when they fall out of the window.
+type Aggregator struct {
+	o       *EventSummaryOptions
+	grouper *eventGrouper
+	// history keeps deleted events until they fall out of the window, as
+	// the local store does for windows longer than the event TTL
+	history bool
+	now     func() time.Time
+
+	mu     sync.Mutex
+	events map[string]*aggregatedEvent
+	totals types.Totals
+	// members holds the events of each group by event key
+	members map[string]map[string]corev1.Event
+	// expiry orders the events in the window by when they were last seen
+	expiry expiryQueue
+}
+
+// aggregatedEvent is an event held by the aggregator
+type aggregatedEvent struct {
+	event    corev1.Event
+	lastSeen time.Time
+	// inWindow is set while the event is in the window and in expiry;
+	// grouped while it is also counted in the group groupKey
+	inWindow bool
+	grouped  bool
+	groupKey string
+	// deleted is set for events the API server no longer holds
+	deleted bool
+}
+
+// NewAggregator returns an empty aggregator summarizing events with the
+// options' window, grouping and filters
+func (o *EventSummaryOptions) NewAggregator() (*Aggregator, error) {
+	grouper, err := newEventGrouper(o.GroupBy, o.Filter, o.Severity)
+	if err != nil {
+		return nil, err
+	}
+	grouper.keepEvents = false
+	return &Aggregator{
+		o:       o,
+		grouper: grouper,
+		history: o.store != nil && o.lookback() > o.EventTTL,
+		now:     time.Now,
+		events:  make(map[string]*aggregatedEvent),
+		members: make(map[string]map[string]corev1.Event),
+	}, nil
+}
+
+// AddStored adds the events of the window kept in the local store, when the
+// window reaches back further than the event TTL. They are held as deleted
+// until the informer adds them.
+func (a *Aggregator) AddStored() error {
+	if !a.history {
+		return nil
+	}
+
+	o := a.o
+	namespace := ""
+	if !o.AllNs {
+		namespace, _, _ = o.ConfigFlags.ToRawKubeConfigLoader().Namespace()
+	}
+	stored, err := o.store.Query(o.storeCluster, a.now().Add(-o.Since))
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, event := range stored {
+		if namespace == "" || event.Namespace == namespace {
+			a.set(event, true)
+		}
+	}
+	return nil
+}
+
+// Add adds an event, or updates it if it is already held
+func (a *Aggregator) Add(event corev1.Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.set(event, false)
+}
+
+// Delete removes an event the API server deleted. With history, it is kept
+// until it falls out of the window.
+func (a *Aggregator) Delete(event corev1.Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := aggregateKey(event)
+	held := a.events[key]
+	if held == nil {
+		return
+	}
+	if a.history && held.inWindow {
+		held.deleted = true
+		return
+	}
+	a.drop(key, held)
+}
+
+// Summary expires the events that fell out of the window and returns the
+// summary of the others, as of now
+func (a *Aggregator) Summary() *types.Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	a.expire(now)
+
+	// Copy the groups, which keep changing after the summary is returned
+	groups := make(map[string]*types.GroupSummary, len(a.grouper.groups))
+	for key, group := range a.grouper.groups {
+		copied := newGroupSummary()
+		copied.Total, copied.Warnings, copied.Errors = group.Total, group.Warnings, group.Errors
+		for t, n := range group.Types {
+			copied.Types[t] = n
+		}
+		for reason, n := range group.Reasons {
+			copied.Reasons[reason] = n
+		}
+		for _, event := range sortedMembers(a.members[key]) {
+			copied.AddEvent(event, "")
+		}
+		groups[key] = copied
+	}
+
+	cluster := clusterEvents{Total: a.totals.Total, Warnings: a.totals.Warnings, Errors: a.totals.Errors}
+	summary := a.o.buildSummary(groups, a.grouper.keys(), []clusterEvents{cluster}, now.Add(-a.o.Since), now)
+	summary.Thresholds = a.o.evaluateThresholds(summary)
+	return summary
+}
+
+// set holds the event in place of any previous version of it, grouping it
+// if it is in the window. A deleted event does not replace a live one.
+func (a *Aggregator) set(event corev1.Event, deleted bool) {
+	key := aggregateKey(event)
+	if held := a.events[key]; held != nil {
+		if deleted && !held.deleted {
+			return
+		}
+		a.drop(key, held)
+	}
+
+	held := &aggregatedEvent{event: event, lastSeen: types.EventTime(event), deleted: deleted}
+	now := a.now()
+	if held.lastSeen.Before(now.Add(-a.o.Since)) {
+		if !deleted {
+			a.events[key] = held
+			a.count(event, 1)
+		}
+		return
+	}
+	a.events[key] = held
+	a.count(event, 1)
+	held.inWindow = true
+	heap.Push(&a.expiry, held)
+
+	if !matchesSearch(event, a.o.Search) {
+		return
+	}
+	if groupKey, ok := a.grouper.add(event, ""); ok {
+		held.grouped, held.groupKey = true, groupKey
+		if a.members[groupKey] == nil {
+			a.members[groupKey] = make(map[string]corev1.Event)
+		}
+		a.members[groupKey][key] = event
+	}
+}
+
+// drop removes a held event and its counts
+func (a *Aggregator) drop(key string, held *aggregatedEvent) {
+	a.ungroup(key, held)
+	a.count(held.event, -1)
+	delete(a.events, key)
+}
+
+// ungroup removes a held event from its group
+func (a *Aggregator) ungroup(key string, held *aggregatedEvent) {
+	if !held.grouped {
+		return
+	}
+	a.grouper.remove(held.event, held.groupKey)
+	delete(a.members[held.groupKey], key)
+	if len(a.members[held.groupKey]) == 0 {
+		delete(a.members, held.groupKey)
+	}
+	held.grouped = false
+}
+
+// expire ungroups the events last seen before the window, and drops the
+// deleted ones
+func (a *Aggregator) expire(now time.Time) {
+	windowStart := now.Add(-a.o.Since)
+	for a.expiry.Len() > 0 && a.expiry[0].lastSeen.Before(windowStart) {
+		held := heap.Pop(&a.expiry).(*aggregatedEvent)
+		key := aggregateKey(held.event)
+		if a.events[key] != held {
+			// Replaced by a newer version or already dropped
+			continue
+		}
+		held.inWindow = false
+		a.ungroup(key, held)
+		if held.deleted {
+			a.drop(key, held)
+		}
+	}
+}
+
+// count adds the event to the totals of all held events, or removes it for
+// a negative sign
+func (a *Aggregator) count(event corev1.Event, sign int) {
+	a.totals.Total += sign
+	if event.Type == "Warning" {
+		a.totals.Warnings += sign
+		if isErrorEvent(event) {
+			a.totals.Errors += sign
+		}
+	}
+}
+
+// aggregateKey identifies an event by namespace and name, like the informer
+func aggregateKey(event corev1.Event) string {
+	return event.Namespace + "/" + event.Name
+}
+
+// sortedMembers returns the events of a group, last seen first
+func sortedMembers(members map[string]corev1.Event) []corev1.Event {
+	events := make([]corev1.Event, 0, len(members))
+	for _, event := range members {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		ti, tj := types.EventTime(events[i]), types.EventTime(events[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return aggregateKey(events[i]) < aggregateKey(events[j])
+	})
+	return events
+}
+
+// expiryQueue is a heap of held events, the earliest last seen first
+type expiryQueue []*aggregatedEvent
+
+func (q expiryQueue) Len() int            { return len(q) }
+func (q expiryQueue) Less(i, j int) bool  { return q[i].lastSeen.Before(q[j].lastSeen) }
+func (q expiryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *expiryQueue) Push(x interface{}) { *q = append(*q, x.(*aggregatedEvent)) }
+func (q *expiryQueue) Pop() interface{} {
+	old := *q
+	held := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return held
+}
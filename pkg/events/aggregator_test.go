@@ -0,0 +1,179 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// aggregatedTestEvent returns an event of the pod last seen at last
+func aggregatedTestEvent(name, namespace, pod, eventType, reason string, last time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: pod},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " for " + pod,
+		LastTimestamp:  metav1.NewTime(last),
+		Count:          1,
+	}
+}
+
+// groupCounts returns the counts of each group, without the events
+func groupCounts(summary *types.Summary) map[string]types.GroupSummary {
+	counts := make(map[string]types.GroupSummary)
+	for _, group := range summary.Groups {
+		counts[group.Key] = types.GroupSummary{
+			Total:           group.Total,
+			Warnings:        group.Warnings,
+			Errors:          group.Errors,
+			Types:           group.Types,
+			Reasons:         group.Reasons,
+			InitialTotal:    group.InitialTotal,
+			InitialWarnings: group.InitialWarnings,
+			InitialErrors:   group.InitialErrors,
+		}
+	}
+	return counts
+}
+
+func TestAggregatorMatchesSummarize(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	events := []corev1.Event{
+		aggregatedTestEvent("a", "shop", "web-0", "Warning", "BackOff", now.Add(-time.Minute)),
+		aggregatedTestEvent("b", "shop", "web-1", "Warning", "Unhealthy", now.Add(-2*time.Minute)),
+		aggregatedTestEvent("c", "shop", "web-1", "Normal", "Pulled", now.Add(-3*time.Minute)),
+		aggregatedTestEvent("d", "kube-system", "dns-0", "Warning", "FailedMount", now.Add(-4*time.Minute)),
+		// Out of the window, only in the totals
+		aggregatedTestEvent("e", "kube-system", "dns-0", "Warning", "BackOff", now.Add(-time.Hour)),
+	}
+
+	tests := []struct {
+		name     string
+		groupBy  string
+		severity types.Severity
+		filter   string
+		search   string
+	}{
+		{name: "ungrouped", severity: types.SeverityAll},
+		{name: "by reason", groupBy: "reason", severity: types.SeverityAll},
+		{name: "by every dimension", groupBy: "namespace,kind,reason,type", severity: types.SeverityAll},
+		{name: "warnings by namespace", groupBy: "namespace", severity: types.SeverityWarning},
+		{name: "errors only", groupBy: "reason", severity: types.SeverityError},
+		{name: "filtered", groupBy: "namespace,reason", severity: types.SeverityAll, filter: "namespace=shop"},
+		{name: "searched", groupBy: "reason", severity: types.SeverityAll, search: "web-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.Since = 15 * time.Minute
+			o.GroupBy, o.Severity, o.Filter, o.Search = tt.groupBy, tt.severity, tt.filter, tt.search
+
+			a, err := o.NewAggregator()
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.now = func() time.Time { return now }
+			for _, event := range events {
+				a.Add(event)
+			}
+			got := a.Summary()
+
+			cluster := clusterEvents{Events: events, Total: 5, Warnings: 4, Errors: 3}
+			want, _, err := o.summarize([]clusterEvents{cluster}, now, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Totals != want.Totals || got.Filtered != want.Filtered {
+				t.Errorf("got totals %+v and filtered %+v, want %+v and %+v", got.Totals, got.Filtered, want.Totals, want.Filtered)
+			}
+			if !reflect.DeepEqual(groupCounts(got), groupCounts(want)) {
+				t.Errorf("got groups %+v, want %+v", groupCounts(got), groupCounts(want))
+			}
+			for i, group := range got.Groups {
+				if len(group.Events) != len(want.Groups[i].Events) {
+					t.Errorf("group %s: got %d events, want %d", group.Key, len(group.Events), len(want.Groups[i].Events))
+				}
+			}
+			if !got.Since.Equal(want.Since) || !got.Until.Equal(want.Until) {
+				t.Errorf("got window %v to %v, want %v to %v", got.Since, got.Until, want.Since, want.Until)
+			}
+		})
+	}
+}
+
+func TestAggregatorUpdatesAndExpires(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	now := start
+	o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Since = 10 * time.Minute
+	o.GroupBy = "reason"
+	a, err := o.NewAggregator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.now = func() time.Time { return now }
+
+	backOff := aggregatedTestEvent("a", "shop", "web-0", "Warning", "BackOff", start.Add(-5*time.Minute))
+	a.Add(backOff)
+	a.Add(aggregatedTestEvent("b", "shop", "web-1", "Warning", "Unhealthy", start.Add(-time.Minute)))
+
+	keys := func() []string {
+		var keys []string
+		for _, group := range a.Summary().Groups {
+			keys = append(keys, group.Key)
+		}
+		return keys
+	}
+	if got, want := keys(), []string{"reason=BackOff", "reason=Unhealthy"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got groups %v, want %v", got, want)
+	}
+
+	// BackOff falls out of the window but is still held
+	now = start.Add(6 * time.Minute)
+	if got, want := keys(), []string{"reason=Unhealthy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after expiry: got groups %v, want %v", got, want)
+	}
+	if total := a.Summary().Totals.Total; total != 2 {
+		t.Errorf("after expiry: got %d events in total, want 2", total)
+	}
+
+	// Seen again, BackOff comes back once, with its new count
+	backOff.Count = 3
+	backOff.LastTimestamp = metav1.NewTime(now)
+	a.Add(backOff)
+	summary := a.Summary()
+	if len(summary.Groups) != 2 || summary.Groups[0].Total != 1 || summary.Groups[0].Events[0].Count != 3 {
+		t.Errorf("after update: got groups %+v", summary.Groups)
+	}
+
+	// Deleted events leave the totals and groups
+	a.Delete(backOff)
+	summary = a.Summary()
+	if got, want := keys(), []string{"reason=Unhealthy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after delete: got groups %v, want %v", got, want)
+	}
+	if summary.Totals.Total != 1 || summary.Filtered.Total != 1 {
+		t.Errorf("after delete: got totals %+v and filtered %+v", summary.Totals, summary.Filtered)
+	}
+
+	// The ungrouped summary keeps its group when it empties
+	o.GroupBy = ""
+	a, err = o.NewAggregator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.now = func() time.Time { return now }
+	a.Add(backOff)
+	a.Delete(backOff)
+	if summary := a.Summary(); len(summary.Groups) != 1 || summary.Groups[0].Total != 0 {
+		t.Errorf("ungrouped: got groups %+v", summary.Groups)
+	}
+}
@@ -0,0 +1,166 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/notify"
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// AlertName is the alertname label of the alerts sent to Alertmanager
+const AlertName = "KubernetesEventThreshold"
+
+// sendAlerts posts an alert for each group breaching an --alert-on rule. In
+// watch mode, alerts are sent again on each summary and alerts of groups
+// that no longer breach are resolved.
+func (o *EventSummaryOptions) sendAlerts(ctx context.Context, summary *types.Summary) error {
+	alerts := o.buildAlerts(summary)
+
+	firing := make(map[string]notify.Alert)
+	for _, alert := range alerts {
+		firing[alertID(alert)] = alert
+	}
+	now := summary.Until
+	for id, alert := range o.firing {
+		if _, ok := firing[id]; !ok {
+			alert.EndsAt = &now
+			alerts = append(alerts, alert)
+		}
+	}
+	if o.Watch {
+		o.firing = firing
+	}
+
+	if len(alerts) == 0 {
+		return nil
+	}
+	return o.Notify.PostAlerts(ctx, o.AlertmanagerURL, alerts)
+}
+
+// buildAlerts returns an alert for each group breaching an --alert-on rule
+func (o *EventSummaryOptions) buildAlerts(summary *types.Summary) []notify.Alert {
+	var alerts []notify.Alert
+	for _, group := range summary.Groups {
+		var breached []string
+		for _, rule := range o.alertRules {
+			if result := rule.result(rule.groupActual(group)); result.Breached {
+				breached = append(breached, result.String())
+			}
+		}
+		if len(breached) == 0 {
+			continue
+		}
+
+		// Labels identify the alert, so they only hold the group; the
+		// severity may change while the alert fires and is an annotation
+		labels := map[string]string{"alertname": AlertName}
+		if len(summary.GroupBy) > 0 {
+			for i, value := range output.SplitGroupKey(group.Key, summary.GroupBy) {
+				labels[summary.GroupBy[i]] = value
+			}
+		} else {
+			labels["group"] = group.Key
+		}
+		for _, label := range o.AlertLabels {
+			name, value, _ := strings.Cut(label, "=")
+			labels[name] = value
+		}
+
+		alert := notify.Alert{
+			Labels: labels,
+			Annotations: map[string]string{
+				"summary": fmt.Sprintf("%d warnings, %d errors in %s (%s)",
+					group.Warnings, group.Errors, group.Key, output.FormatTopReasons(group.Reasons, 3)),
+				"breached": strings.Join(breached, ", "),
+				"severity": "warning",
+			},
+			StartsAt: summary.Until,
+		}
+		if group.Errors > 0 {
+			alert.Annotations["severity"] = "critical"
+		}
+		if messages := topMessages(group, 3); len(messages) > 0 {
+			alert.Annotations["description"] = strings.Join(messages, "\n")
+		}
+		for _, event := range group.Events {
+			t := event.FirstTimestamp.Time
+			if t.IsZero() {
+				t = types.EventTime(event)
+			}
+			if !t.IsZero() && t.Before(alert.StartsAt) {
+				alert.StartsAt = t
+			}
+		}
+		if o.Watch {
+			// Resolve the alert if no later summary refreshes it, e.g.
+			// because the watch stopped
+			endsAt := summary.Until.Add(3 * o.WatchInterval)
+			alert.EndsAt = &endsAt
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// topMessages returns the most frequent warning messages of the group
+func topMessages(group types.Group, n int) []string {
+	counts := make(map[string]int)
+	for _, event := range group.Events {
+		if event.Type != "Warning" {
+			continue
+		}
+		message := fmt.Sprintf("%s %s/%s: %s", event.InvolvedObject.Kind,
+			event.InvolvedObject.Namespace, event.InvolvedObject.Name, event.Message)
+		counts[message] += int(event.Count)
+	}
+
+	var messages []string
+	for message := range counts {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if counts[messages[i]] != counts[messages[j]] {
+			return counts[messages[i]] > counts[messages[j]]
+		}
+		return messages[i] < messages[j]
+	})
+	if len(messages) > n {
+		messages = messages[:n]
+	}
+	return messages
+}
+
+// alertID identifies an alert by its labels
+func alertID(alert notify.Alert) string {
+	var names []string
+	for name := range alert.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%q,", name, alert.Labels[name])
+	}
+	return b.String()
+}
+
+// validAlertLabel reports whether label is a name=value pair with a valid
+// Prometheus label name
+func validAlertLabel(label string) bool {
+	name, _, ok := strings.Cut(label, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/notify"
+)
+
+func TestSendAlerts(t *testing.T) {
+	var posted [][]notify.Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/alerts" {
+			t.Errorf("got %s %s, want POST /api/v2/alerts", r.Method, r.URL.Path)
+		}
+		var alerts []notify.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+			t.Errorf("failed to decode alerts: %v", err)
+		}
+		posted = append(posted, alerts)
+	}))
+	defer server.Close()
+
+	o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.GroupBy = "namespace,reason"
+	o.Since = time.Hour
+	o.AlertmanagerURL = server.URL + "/"
+	o.AlertLabels = []string{"team=web"}
+	o.Watch = true
+	o.WatchInterval = 30 * time.Second
+	rule, err := parseThreshold("alert-on", "warnings>=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.alertRules = append(o.alertRules, rule)
+
+	now := time.Now().Truncate(time.Second)
+	first := now.Add(-40 * time.Minute)
+	events := []corev1.Event{
+		seriesEvent("BackOff", now.Add(-20*time.Minute), now.Add(-time.Minute), 2),
+		seriesEvent("BackOff", first, now.Add(-2*time.Minute), 5),
+		// Below the rule, so not alerted on
+		seriesEvent("Unhealthy", now.Add(-5*time.Minute), now.Add(-5*time.Minute), 1),
+	}
+	events[0].InvolvedObject.Name = "web-1"
+	events[0].Message = "Back-off restarting failed container web"
+	events[1].Message = "Back-off pulling image web:broken"
+
+	// The first summary fires the alert
+	summary, _, err := o.summarize([]clusterEvents{{Events: events}}, now, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.sendAlerts(context.Background(), summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 1 || len(posted[0]) != 1 {
+		t.Fatalf("got %v, want one post of one alert", posted)
+	}
+	alert := posted[0][0]
+
+	wantLabels := map[string]string{
+		"alertname": AlertName,
+		"namespace": "default",
+		"reason":    "BackOff",
+		"team":      "web",
+	}
+	if !reflect.DeepEqual(alert.Labels, wantLabels) {
+		t.Errorf("got labels %v, want %v", alert.Labels, wantLabels)
+	}
+	wantDescription := "Pod default/web-0: Back-off pulling image web:broken\n" +
+		"Pod default/web-1: Back-off restarting failed container web"
+	if got := alert.Annotations["description"]; got != wantDescription {
+		t.Errorf("got description %q, want %q", got, wantDescription)
+	}
+	if got := alert.Annotations["breached"]; got != "warnings>=2 (warnings=2)" {
+		t.Errorf("got breached %q", got)
+	}
+	if got := alert.Annotations["severity"]; got != "critical" {
+		t.Errorf("got severity %q, want critical", got)
+	}
+	if !alert.StartsAt.Equal(first) {
+		t.Errorf("got startsAt %s, want the first event time %s", alert.StartsAt, first)
+	}
+	if wantEnd := now.Add(3 * o.WatchInterval); alert.EndsAt == nil || !alert.EndsAt.Equal(wantEnd) {
+		t.Errorf("got endsAt %v, want %s while firing", alert.EndsAt, wantEnd)
+	}
+
+	// The next summary no longer breaches the rule and resolves the alert
+	later := now.Add(o.WatchInterval)
+	summary, _, err = o.summarize([]clusterEvents{{Events: events[2:]}}, later, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.sendAlerts(context.Background(), summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 2 || len(posted[1]) != 1 {
+		t.Fatalf("got %v, want a second post of one alert", posted)
+	}
+	resolved := posted[1][0]
+	if !reflect.DeepEqual(resolved.Labels, wantLabels) {
+		t.Errorf("got resolved labels %v, want %v", resolved.Labels, wantLabels)
+	}
+	if resolved.EndsAt == nil || !resolved.EndsAt.Equal(later) {
+		t.Errorf("got endsAt %v, want the resolving summary time %s", resolved.EndsAt, later)
+	}
+
+	// Once resolved, nothing is left to send
+	if err := o.sendAlerts(context.Background(), summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 2 {
+		t.Errorf("got %d posts, want no post after resolving", len(posted))
+	}
+}
+
+func TestAlertSeverityChangeKeepsAlert(t *testing.T) {
+	var posted [][]notify.Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []notify.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+			t.Errorf("failed to decode alerts: %v", err)
+		}
+		posted = append(posted, alerts)
+	}))
+	defer server.Close()
+
+	o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.GroupBy = "namespace"
+	o.Since = time.Hour
+	o.AlertmanagerURL = server.URL
+	o.Watch = true
+	o.WatchInterval = 30 * time.Second
+	rule, err := parseThreshold("alert-on", "warnings>0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.alertRules = append(o.alertRules, rule)
+
+	now := time.Now().Truncate(time.Second)
+	unhealthy := seriesEvent("Unhealthy", now.Add(-10*time.Minute), now.Add(-time.Minute), 3)
+	backOff := seriesEvent("BackOff", now.Add(-time.Minute), now, 1)
+
+	// The group is a warning first, then critical once it has an error
+	for _, events := range [][]corev1.Event{{unhealthy}, {unhealthy, backOff}} {
+		summary, _, err := o.summarize([]clusterEvents{{Events: events}}, now, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := o.sendAlerts(context.Background(), summary); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(posted) != 2 || len(posted[0]) != 1 || len(posted[1]) != 1 {
+		t.Fatalf("got %v, want two posts of one alert", posted)
+	}
+	before, after := posted[0][0], posted[1][0]
+	if !reflect.DeepEqual(before.Labels, after.Labels) {
+		t.Errorf("got labels %v, then %v; want the same alert", before.Labels, after.Labels)
+	}
+	if before.Annotations["severity"] != "warning" || after.Annotations["severity"] != "critical" {
+		t.Errorf("got severity %q, then %q; want warning, then critical",
+			before.Annotations["severity"], after.Annotations["severity"])
+	}
+	if after.EndsAt == nil || !after.EndsAt.After(now) {
+		t.Errorf("got endsAt %v, want the alert still firing", after.EndsAt)
+	}
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// lookback returns how far back events are needed: the summary window and,
+// for live anomaly baselines, the baseline windows preceding it
+func (o *EventSummaryOptions) lookback() time.Duration {
+	if o.Anomalies && o.baseline == nil {
+		return o.Since * time.Duration(o.BaselineWindows+1)
+	}
+	return o.Since
+}
+
+// detectAnomalies compares the count of each group in the summary with its
+// counts in the baseline windows and returns the groups deviating by at
+// least AnomalyThreshold standard deviations, most abnormal first
+func (o *EventSummaryOptions) detectAnomalies(summary *types.Summary, clusters []clusterEvents, now time.Time) ([]types.Anomaly, error) {
+	// Baseline windows precede the summary window, or end when the
+	// baseline snapshot was recorded. Each window holds the events whose
+	// span from first to last seen overlaps it, so a series repeating
+	// since before the baseline counts in every window, not only the
+	// current one.
+	end := now.Add(-o.Since)
+	if o.baseline != nil {
+		clusters, end = snapshotClusters(o.baseline), o.baseline.RecordedAt
+	}
+
+	counts := make(map[string][]int)
+	for i := 0; i < o.BaselineWindows; i++ {
+		window, _, err := o.summarize(clustersUntil(clusters, end), end, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range window.Groups {
+			if counts[group.Key] == nil {
+				counts[group.Key] = make([]int, o.BaselineWindows)
+			}
+			counts[group.Key][i] = group.Total
+		}
+		end = end.Add(-o.Since)
+	}
+
+	current := make(map[string]int)
+	for _, group := range summary.Groups {
+		current[group.Key] = group.Total
+		if counts[group.Key] == nil {
+			counts[group.Key] = make([]int, o.BaselineWindows)
+		}
+	}
+
+	anomalies := []types.Anomaly{}
+	for key, baseline := range counts {
+		mean, stddev := meanStdDev(baseline)
+		anomaly := types.Anomaly{
+			Key:            key,
+			Current:        current[key],
+			BaselineMean:   mean,
+			BaselineStdDev: stddev,
+			ZScore:         (float64(current[key]) - mean) / math.Max(stddev, 1),
+			New:            mean == 0,
+		}
+		if mean > 0 {
+			anomaly.Ratio = float64(current[key]) / mean
+		}
+		if math.Abs(anomaly.ZScore) >= o.AnomalyThreshold && (anomaly.Current > 0 || mean > 0) {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		a, b := math.Abs(anomalies[i].ZScore), math.Abs(anomalies[j].ZScore)
+		if a != b {
+			return a > b
+		}
+		return anomalies[i].Key < anomalies[j].Key
+	})
+	return anomalies, nil
+}
+
+func meanStdDev(counts []int) (float64, float64) {
+	if len(counts) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, c := range counts {
+		sum += float64(c)
+	}
+	mean := sum / float64(len(counts))
+
+	var variance float64
+	for _, c := range counts {
+		variance += (float64(c) - mean) * (float64(c) - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(counts)))
+}
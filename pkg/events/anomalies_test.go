@@ -0,0 +1,56 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.GroupBy = "reason"
+	o.Since = 15 * time.Minute
+	o.Anomalies = true
+	o.BaselineWindows = 3
+	o.AnomalyThreshold = 2
+
+	events := []corev1.Event{
+		// Seen once in the second baseline window only
+		seriesEvent("FailedScheduling", now.Add(-35*time.Minute), now.Add(-35*time.Minute), 1),
+	}
+	for i := 0; i < 5; i++ {
+		// Repeating for four hours, through all baseline windows
+		event := seriesEvent("BackOff", now.Add(-4*time.Hour), now.Add(-time.Minute), 400)
+		event.Name = fmt.Sprintf("backoff-%d", i)
+		event.InvolvedObject.Name = fmt.Sprintf("web-%d", i)
+		events = append(events, event)
+
+		// A spike of a reason absent from the baseline
+		event = seriesEvent("Unhealthy", now.Add(-5*time.Minute), now.Add(-2*time.Minute), 3)
+		event.Name = fmt.Sprintf("unhealthy-%d", i)
+		event.InvolvedObject.Name = fmt.Sprintf("web-%d", i)
+		events = append(events, event)
+	}
+	clusters := []clusterEvents{{Events: events}}
+
+	summary, _, err := o.summarize(clusters, now, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anomalies, err := o.detectAnomalies(summary, clusters, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(anomalies) != 1 {
+		t.Fatalf("got %d anomalies, want 1: %+v", len(anomalies), anomalies)
+	}
+	spike := anomalies[0]
+	if spike.Key != "reason=Unhealthy" || !spike.New || spike.Current != 5 || spike.ZScore != 5 {
+		t.Errorf("got %+v, want a new reason=Unhealthy group of 5 with z=5", spike)
+	}
+}
@@ -0,0 +1,326 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/snapshot"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// DiffOptions contains the options for the diff command
+type DiffOptions struct {
+	// Summary holds the fetch, grouping and filter settings of both windows
+	Summary *EventSummaryOptions
+	Format  string
+
+	// Before and After are the lengths of the two windows. Live, the after
+	// window ends now and the before window ends where it starts.
+	Before time.Duration
+	After  time.Duration
+
+	// BeforeSnapshot and AfterSnapshot take a window from a recorded
+	// snapshot, ending when it was recorded, instead of from live events
+	BeforeSnapshot string
+	AfterSnapshot  string
+	before, after  *types.Snapshot
+
+	genericclioptions.IOStreams
+}
+
+// NewDiffOptions returns initialized DiffOptions
+func NewDiffOptions(streams genericclioptions.IOStreams) *DiffOptions {
+	summary := NewEventSummaryOptions(streams)
+	summary.Format = "wide"
+	summary.SortBy = "lastTimestamp"
+	return &DiffOptions{
+		Summary:   summary,
+		IOStreams: streams,
+	}
+}
+
+// Complete completes all the required options
+func (o *DiffOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.Summary.Complete(cmd, args); err != nil {
+		return err
+	}
+
+	var err error
+	if o.BeforeSnapshot != "" {
+		if o.before, err = snapshot.Read(o.BeforeSnapshot); err != nil {
+			return err
+		}
+	}
+	if o.AfterSnapshot != "" {
+		if o.after, err = snapshot.Read(o.AfterSnapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate validates the provided options
+func (o *DiffOptions) Validate() error {
+	switch o.Format {
+	case "wide", "json", "yaml":
+	default:
+		return fmt.Errorf("invalid output format: %s, must be one of: wide, json, yaml", o.Format)
+	}
+
+	if o.Before <= 0 || o.After <= 0 {
+		return fmt.Errorf("--before and --after must be positive durations")
+	}
+
+	s := o.Summary
+	if o.before != nil && o.after != nil &&
+		(s.AllContexts || len(s.Contexts) > 0 || len(s.Namespaces) > 0 || s.AllNs ||
+			len(s.ResourceArgs) > 0 || s.Selector != "" || s.Recursive) {
+		return fmt.Errorf("fetch flags and resource arguments cannot be used when both windows come from snapshots")
+	}
+	return s.Validate()
+}
+
+// Run summarizes both windows and prints how their groups differ
+func (o *DiffOptions) Run() error {
+	s := o.Summary
+
+	var live []clusterEvents
+	now := time.Now()
+	if o.before == nil || o.after == nil {
+		var err error
+		if live, err = s.fetchEvents(context.TODO()); err != nil {
+			return err
+		}
+	}
+
+	// The live before window ends where the live after window starts
+	beforeUntil := now
+	if o.after == nil {
+		beforeUntil = now.Add(-o.After)
+	}
+
+	before, err := o.summarizeWindow(o.before, clustersUntil(live, beforeUntil), o.Before, beforeUntil)
+	if err != nil {
+		return err
+	}
+	after, err := o.summarizeWindow(o.after, live, o.After, now)
+	if err != nil {
+		return err
+	}
+
+	diff := diffSummaries(before, after)
+	diff.Before.Snapshot = o.BeforeSnapshot
+	diff.After.Snapshot = o.AfterSnapshot
+	return output.WriteDiff(o.Out, diff, o.Format, output.ColorEnabled(o.Out))
+}
+
+// summarizeWindow summarizes the window of length since ending at until,
+// over the snapshot if given and otherwise over the live events. Each window
+// is summarized with its own copy of the options, so the other window's
+// snapshot and length are left untouched.
+func (o *DiffOptions) summarizeWindow(snap *types.Snapshot, live []clusterEvents, since time.Duration, until time.Time) (*types.Summary, error) {
+	s := *o.Summary
+	s.snapshot = snap
+	s.Since = since
+
+	clusters := live
+	if snap != nil {
+		clusters, until = snapshotClusters(snap), snap.RecordedAt
+	}
+	summary, _, err := s.summarize(clusters, until, nil)
+	return summary, err
+}
+
+// clustersUntil returns the clusters without the events first seen after
+// until. An event is one series of occurrences, so a series that started
+// before until and is still repeating is kept: summarized with until as the
+// current time, it belongs to every window its span overlaps.
+func clustersUntil(clusters []clusterEvents, until time.Time) []clusterEvents {
+	result := make([]clusterEvents, 0, len(clusters))
+	for _, cluster := range clusters {
+		events := cluster.Events
+		cluster.Events = nil
+		for _, event := range events {
+			if !firstSeen(event).After(until) {
+				cluster.Events = append(cluster.Events, event)
+			}
+		}
+		result = append(result, cluster)
+	}
+	return result
+}
+
+// firstSeen returns when an event series was first seen, preferring
+// FirstTimestamp, then EventTime, then the time it was last seen
+func firstSeen(event corev1.Event) time.Time {
+	if t := event.FirstTimestamp.Time; !t.IsZero() {
+		return t
+	}
+	if t := event.EventTime.Time; !t.IsZero() {
+		return t
+	}
+	return types.EventTime(event)
+}
+
+// diffStatusOrder lists new groups first and unchanged groups last
+var diffStatusOrder = map[types.DiffStatus]int{
+	types.DiffNew:       0,
+	types.DiffChanged:   1,
+	types.DiffRemoved:   2,
+	types.DiffUnchanged: 3,
+}
+
+// diffSummaries compares the groups of two summaries by group key. An
+// event is one series of occurrences that may overlap both windows, so
+// groups are compared by their occurrences in each window rather than by
+// their number of events.
+func diffSummaries(before, after *types.Summary) *types.Diff {
+	diff := &types.Diff{
+		Before:  types.DiffWindow{Since: before.Since, Until: before.Until},
+		After:   types.DiffWindow{Since: after.Since, Until: after.Until},
+		GroupBy: after.GroupBy,
+		Groups:  []types.GroupDiff{},
+	}
+	diff.Kind = types.DiffKind
+	diff.APIVersion = types.SummaryAPIVersion
+
+	seenReasons := make(map[string]bool)
+	beforeGroups := make(map[string]*types.GroupSummary)
+	for _, group := range before.Groups {
+		beforeGroups[group.Key] = group.GroupSummary
+		addTotals(&diff.Before.Totals, windowTotals(group.GroupSummary, before.Since, before.Until))
+		for reason := range group.Reasons {
+			seenReasons[reason] = true
+		}
+	}
+
+	newReasons := make(map[string]bool)
+	afterGroups := make(map[string]bool)
+	for _, group := range after.Groups {
+		afterGroups[group.Key] = true
+		d := types.GroupDiff{Key: group.Key, After: windowTotals(group.GroupSummary, after.Since, after.Until)}
+		addTotals(&diff.After.Totals, d.After)
+		if previous, ok := beforeGroups[group.Key]; ok {
+			d.Before = windowTotals(previous, before.Since, before.Until)
+		}
+		for reason := range group.Reasons {
+			if !seenReasons[reason] {
+				d.NewReasons = append(d.NewReasons, reason)
+				newReasons[reason] = true
+			}
+		}
+		sort.Strings(d.NewReasons)
+		diff.Groups = append(diff.Groups, d)
+	}
+	for _, group := range before.Groups {
+		if !afterGroups[group.Key] {
+			diff.Groups = append(diff.Groups, types.GroupDiff{Key: group.Key, Before: windowTotals(group.GroupSummary, before.Since, before.Until)})
+		}
+	}
+
+	for i := range diff.Groups {
+		d := &diff.Groups[i]
+		d.Delta = types.Totals{
+			Total:    d.After.Total - d.Before.Total,
+			Warnings: d.After.Warnings - d.Before.Warnings,
+			Errors:   d.After.Errors - d.Before.Errors,
+		}
+		_, inBefore := beforeGroups[d.Key]
+		switch {
+		case !inBefore:
+			d.Status = types.DiffNew
+		case !afterGroups[d.Key]:
+			d.Status = types.DiffRemoved
+		case d.Delta != types.Totals{}:
+			d.Status = types.DiffChanged
+		default:
+			d.Status = types.DiffUnchanged
+		}
+	}
+
+	sort.SliceStable(diff.Groups, func(i, j int) bool {
+		a, b := diff.Groups[i], diff.Groups[j]
+		if a.Status != b.Status {
+			return diffStatusOrder[a.Status] < diffStatusOrder[b.Status]
+		}
+		if abs(a.Delta.Total) != abs(b.Delta.Total) {
+			return abs(a.Delta.Total) > abs(b.Delta.Total)
+		}
+		return a.Key < b.Key
+	})
+
+	for reason := range newReasons {
+		diff.NewReasons = append(diff.NewReasons, reason)
+	}
+	sort.Strings(diff.NewReasons)
+	return diff
+}
+
+// windowTotals counts the occurrences of the group's events between since
+// and until
+func windowTotals(group *types.GroupSummary, since, until time.Time) types.Totals {
+	var totals types.Totals
+	for _, event := range group.Events {
+		n := windowOccurrences(event, since, until)
+		totals.Total += n
+		if event.Type == "Warning" {
+			totals.Warnings += n
+			if isErrorEvent(event) {
+				totals.Errors += n
+			}
+		}
+	}
+	return totals
+}
+
+// windowOccurrences estimates how many occurrences of an event series fell
+// between since and until, assuming they were spread evenly from when it was
+// first seen to when it was last seen. A series in the window counts at
+// least once.
+func windowOccurrences(event corev1.Event, since, until time.Time) int {
+	count := int(event.Count)
+	if event.Series != nil && int(event.Series.Count) > count {
+		count = int(event.Series.Count)
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	first, last := firstSeen(event), types.EventTime(event)
+	span := last.Sub(first)
+	if span <= 0 {
+		return count
+	}
+	if first.Before(since) {
+		first = since
+	}
+	if last.After(until) {
+		last = until
+	}
+	n := int(math.Round(float64(count) * float64(last.Sub(first)) / float64(span)))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func addTotals(totals *types.Totals, add types.Totals) {
+	totals.Total += add.Total
+	totals.Warnings += add.Warnings
+	totals.Errors += add.Errors
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
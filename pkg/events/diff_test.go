@@ -0,0 +1,106 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// seriesEvent returns an event repeated count times between first and last
+func seriesEvent(reason string, first, last time.Time, count int32) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: reason, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-0"},
+		Reason:         reason,
+		Message:        reason + " happened",
+		Type:           corev1.EventTypeWarning,
+		FirstTimestamp: metav1.NewTime(first),
+		LastTimestamp:  metav1.NewTime(last),
+		Count:          count,
+	}
+}
+
+func TestDiffWindowsBySpan(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	o := NewDiffOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Summary.GroupBy = "reason"
+	o.Before, o.After = time.Hour, 30*time.Minute
+
+	live := []clusterEvents{{Events: []corev1.Event{
+		// Repeating once a minute for three hours and still going
+		seriesEvent("BackOff", now.Add(-3*time.Hour), now, 180),
+		// Repeating once a minute since 45 minutes ago, so more often in
+		// the after window although it is one event in both
+		seriesEvent("FailedScheduling", now.Add(-45*time.Minute), now, 45),
+		// Only happened in the before window
+		seriesEvent("FailedMount", now.Add(-70*time.Minute), now.Add(-60*time.Minute), 2),
+		// Only happened in the after window
+		seriesEvent("Unhealthy", now.Add(-10*time.Minute), now.Add(-5*time.Minute), 3),
+	}}}
+
+	beforeUntil := now.Add(-o.After)
+	before, err := o.summarizeWindow(nil, clustersUntil(live, beforeUntil), o.Before, beforeUntil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := o.summarizeWindow(nil, live, o.After, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Summary.Since != 0 || o.Summary.snapshot != nil {
+		t.Errorf("summarizing the windows changed the options: since %v", o.Summary.Since)
+	}
+	if !before.Since.Equal(now.Add(-90*time.Minute)) || !after.Since.Equal(now.Add(-30*time.Minute)) {
+		t.Errorf("got windows from %v and %v", before.Since, after.Since)
+	}
+
+	want := map[string]struct {
+		status        types.DiffStatus
+		before, after int
+	}{
+		"reason=BackOff":          {types.DiffChanged, 60, 30},
+		"reason=FailedScheduling": {types.DiffChanged, 15, 30},
+		"reason=FailedMount":      {types.DiffRemoved, 2, 0},
+		"reason=Unhealthy":        {types.DiffNew, 0, 3},
+	}
+	diff := diffSummaries(before, after)
+	if len(diff.Groups) != len(want) {
+		t.Fatalf("got %d groups, want %d: %+v", len(diff.Groups), len(want), diff.Groups)
+	}
+	for _, group := range diff.Groups {
+		w := want[group.Key]
+		if group.Status != w.status || group.Before.Total != w.before || group.After.Total != w.after {
+			t.Errorf("group %s: got %s %d -> %d, want %s %d -> %d", group.Key,
+				group.Status, group.Before.Total, group.After.Total, w.status, w.before, w.after)
+		}
+	}
+	if diff.Before.Total != 77 || diff.After.Total != 63 {
+		t.Errorf("got totals %d -> %d, want 77 -> 63", diff.Before.Total, diff.After.Total)
+	}
+}
+
+func TestWindowOccurrences(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name  string
+		event corev1.Event
+		since time.Time
+		want  int
+	}{
+		{"whole series", seriesEvent("BackOff", now.Add(-time.Hour), now, 60), now.Add(-2 * time.Hour), 60},
+		{"half the series", seriesEvent("BackOff", now.Add(-time.Hour), now, 60), now.Add(-30 * time.Minute), 30},
+		{"single occurrence", seriesEvent("Pulled", now.Add(-time.Hour), now.Add(-time.Hour), 1), now.Add(-2 * time.Hour), 1},
+		{"at least once", seriesEvent("BackOff", now.Add(-time.Hour), now, 3), now.Add(-time.Second), 1},
+		{"no count", seriesEvent("Scheduled", now.Add(-time.Minute), now.Add(-time.Minute), 0), now.Add(-time.Hour), 1},
+	}
+	for _, tt := range tests {
+		if got := windowOccurrences(tt.event, tt.since, now); got != tt.want {
+			t.Errorf("%s: got %d occurrences, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// exportsEvents reports whether a notification or export needs the events
+// of the summary, not just its counts
+func (o *EventSummaryOptions) exportsEvents() bool {
+	return o.Notify.Enabled() || o.AlertmanagerURL != "" || o.exportsLogs()
+}
+
+// exportsLogs reports whether events are exported to a log store
+func (o *EventSummaryOptions) exportsLogs() bool {
+	return o.OTLP.URL != "" || o.Loki.URL != "" || o.Elasticsearch.URL != "" || o.CloudEvents.URL != ""
+}
+
+// exportLogs exports the events of the summary to the OTLP collector, Loki,
+// Elasticsearch and the CloudEvents sink. In watch mode, events exported by the previous summary
+// are left out.
+func (o *EventSummaryOptions) exportLogs(ctx context.Context, summary *types.Summary) error {
+	if o.Watch {
+		previous := o.exported
+		o.exported = make(map[string]bool)
+		summary = filterEvents(summary, func(event corev1.Event) bool {
+			id := string(event.UID) + "/" + event.ResourceVersion
+			o.exported[id] = true
+			return !previous[id]
+		})
+	}
+
+	if o.OTLP.URL != "" {
+		if err := o.Notify.ExportLogs(ctx, &o.OTLP, summary, time.Now()); err != nil {
+			return err
+		}
+	}
+	if o.Loki.URL != "" {
+		if err := o.Notify.PushLoki(ctx, &o.Loki, summary); err != nil {
+			return err
+		}
+	}
+	if o.Elasticsearch.URL != "" {
+		if err := o.Notify.BulkIndex(ctx, &o.Elasticsearch, o.ESIndex, summary); err != nil {
+			return err
+		}
+	}
+	if o.CloudEvents.URL != "" {
+		if err := o.Notify.SendCloudEvents(ctx, &o.CloudEvents, summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterEvents returns a copy of the summary whose groups only hold the
+// events keep returns true for; the group counts are unchanged
+func filterEvents(summary *types.Summary, keep func(corev1.Event) bool) *types.Summary {
+	filtered := *summary
+	filtered.Groups = make([]types.Group, 0, len(summary.Groups))
+	for _, group := range summary.Groups {
+		filtered.Groups = append(filtered.Groups, types.Group{Key: group.Key, GroupSummary: group.FilterEvents(keep)})
+	}
+	return &filtered
+}
@@ -0,0 +1,344 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// clusterEvents holds the events fetched from a single cluster
+type clusterEvents struct {
+	Name string
+	// Namespace is the namespace events were listed in, empty for all
+	Namespace string
+	// Targets lists the objects the resource arguments and selector resolved to
+	Targets []types.ObjectReference
+	// Owners maps the Pods of problem events to their workloads
+	Owners map[objectRef]types.ObjectReference
+	// Events holds the events of the targeted objects, or all listed events
+	// when no resources were given
+	Events []corev1.Event
+	// Fetched holds all listed events, before narrowing to the targets
+	Fetched []corev1.Event
+	// Total, Warnings and Errors count all listed events before any filtering
+	Total    int
+	Warnings int
+	Errors   int
+	// Forbidden lists the namespaces whose events could not be listed
+	// when falling back to per-namespace listing
+	Forbidden []string
+	// Err is set when the cluster could not be reached
+	Err error
+}
+
+// multiCluster reports whether events are fetched from several contexts,
+// or were recorded from several contexts in the replayed snapshot
+func (o *EventSummaryOptions) multiCluster() bool {
+	if o.snapshot != nil {
+		return o.snapshot.MultiCluster
+	}
+	return o.AllContexts || len(o.Contexts) > 0
+}
+
+// fetchEvents fetches the events from the current context or, in
+// multi-cluster mode, from every requested context in parallel. Unreachable
+// clusters are reported in the result rather than failing the whole run.
+func (o *EventSummaryOptions) fetchEvents(ctx context.Context) ([]clusterEvents, error) {
+	if !o.multiCluster() {
+		result, err := o.fetchClusterEvents(ctx, o.ConfigFlags, o.currentContext(), true)
+		if err != nil {
+			return nil, err
+		}
+		return []clusterEvents{result}, nil
+	}
+
+	names, err := o.contextNames()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]clusterEvents, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = o.fetchClusterWithTimeout(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	var failures []string
+	for _, result := range results {
+		if result.Err == nil {
+			return results, nil
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", result.Name, result.Err))
+	}
+	return nil, fmt.Errorf("failed to fetch events from any cluster: %s", strings.Join(failures, "; "))
+}
+
+// fetchClusterWithTimeout fetches the events of a single context, giving up
+// after the per-cluster timeout
+func (o *EventSummaryOptions) fetchClusterWithTimeout(ctx context.Context, name string) clusterEvents {
+	ctx, cancel := context.WithTimeout(ctx, o.ClusterTimeout)
+	defer cancel()
+
+	done := make(chan clusterEvents, 1)
+	go func() {
+		result, err := o.fetchClusterEvents(ctx, o.configFlagsForContext(name), name, false)
+		if err != nil {
+			result = clusterEvents{Name: name, Err: err}
+		}
+		done <- result
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		return clusterEvents{Name: name, Err: fmt.Errorf("timed out after %s", o.ClusterTimeout)}
+	}
+}
+
+// fetchClusterEvents lists the events of a single cluster and narrows them
+// down to the targeted objects
+func (o *EventSummaryOptions) fetchClusterEvents(ctx context.Context, flags *genericclioptions.ConfigFlags, name string, verbose bool) (clusterEvents, error) {
+	result := clusterEvents{Name: name}
+
+	config, err := flags.ToRESTConfig()
+	if err != nil {
+		return result, fmt.Errorf("failed to get client config: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return result, fmt.Errorf("failed to create clientset: %v", err)
+	}
+
+	var namespace string
+	if !o.AllNs && len(o.Namespaces) == 0 {
+		var explicit bool
+		namespace, explicit, err = flags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return result, fmt.Errorf("failed to get namespace: %v", err)
+		}
+		if !explicit && verbose {
+			fmt.Fprintf(o.ErrOut, "Using namespace %q\n", namespace)
+		}
+	}
+
+	// Resolve the objects whose events should be summarized, if any were given
+	var targets *targetSet
+	if len(o.ResourceArgs) > 0 || o.Selector != "" {
+		targets, err = o.resolveTargets(ctx, flags, clientset, namespace)
+		if err != nil {
+			return result, err
+		}
+		// Events of cluster-scoped objects are not recorded in the object's namespace
+		if targets.clusterScoped {
+			namespace = ""
+		}
+		result.Targets = targets.objects
+	}
+	result.Namespace = namespace
+
+	items, forbidden, err := o.listEvents(ctx, clientset, namespace)
+	if err != nil {
+		return result, err
+	}
+	result.Forbidden = forbidden
+	result.Fetched = items
+
+	for _, event := range items {
+		result.Total++
+		if event.Type == "Warning" {
+			result.Warnings++
+			if isErrorEvent(event) {
+				result.Errors++
+			}
+		}
+
+		// Only keep events of the targeted objects
+		if targets != nil && !targets.matches(event) {
+			continue
+		}
+		result.Events = append(result.Events, event)
+	}
+
+	if o.Problems || o.recording {
+		result.Owners = resolveWorkloads(ctx, clientset, result.Events)
+	}
+	return result, nil
+}
+
+// listEvents lists the events of a namespace, or of the whole cluster when
+// namespace is empty. When --namespaces is given, or a cluster-wide list is
+// forbidden, events are listed namespace by namespace instead and the
+// namespaces that are forbidden as well are returned.
+func (o *EventSummaryOptions) listEvents(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]corev1.Event, []string, error) {
+	if namespace == "" && len(o.Namespaces) > 0 {
+		return o.listEventsPerNamespace(ctx, clientset, o.Namespaces)
+	}
+
+	items, err := listNamespaceEvents(ctx, clientset, namespace)
+	if err == nil {
+		return items, nil, nil
+	}
+	if apierrors.IsForbidden(err) && namespace != "" {
+		return nil, nil, fmt.Errorf("failed to list events: %v (run 'kubectl event-summary check-access' to diagnose missing permissions)", err)
+	}
+	if !apierrors.IsForbidden(err) {
+		return nil, nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	namespaces, nsErr := listNamespaces(ctx, clientset)
+	if nsErr != nil {
+		return nil, nil, fmt.Errorf("failed to list events: %v (per-namespace fallback failed: %v; use --namespaces to name them explicitly, or run 'kubectl event-summary check-access' to diagnose missing permissions)", err, nsErr)
+	}
+	if len(namespaces) == 0 {
+		return nil, nil, fmt.Errorf("failed to list events: %v (no namespaces visible for the per-namespace fallback; use --namespaces to name them explicitly, or run 'kubectl event-summary check-access' to diagnose missing permissions)", err)
+	}
+	return o.listEventsPerNamespace(ctx, clientset, namespaces)
+}
+
+// listEventsPerNamespace lists the events of each namespace using at most
+// MaxConcurrency parallel requests
+func (o *EventSummaryOptions) listEventsPerNamespace(ctx context.Context, clientset kubernetes.Interface, namespaces []string) ([]corev1.Event, []string, error) {
+	if len(namespaces) == 0 {
+		return nil, nil, nil
+	}
+
+	type namespaceEvents struct {
+		items []corev1.Event
+		err   error
+	}
+	results := make([]namespaceEvents, len(namespaces))
+
+	workers := o.MaxConcurrency
+	if workers > len(namespaces) {
+		workers = len(namespaces)
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				items, err := listNamespaceEvents(ctx, clientset, namespaces[i])
+				results[i] = namespaceEvents{items: items, err: err}
+			}
+		}()
+	}
+	for i := range namespaces {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var items []corev1.Event
+	var forbidden []string
+	for i, result := range results {
+		if apierrors.IsForbidden(result.err) {
+			forbidden = append(forbidden, namespaces[i])
+			continue
+		}
+		if result.err != nil {
+			return nil, nil, fmt.Errorf("failed to list events in namespace %q: %v", namespaces[i], result.err)
+		}
+		items = append(items, result.items...)
+	}
+	if len(forbidden) == len(namespaces) {
+		return nil, nil, fmt.Errorf("failed to list events: forbidden in all %d namespaces (run 'kubectl event-summary check-access' to diagnose missing permissions)", len(namespaces))
+	}
+	return items, forbidden, nil
+}
+
+func listNamespaceEvents(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]corev1.Event, error) {
+	eventList, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		TimeoutSeconds: ptr.To[int64](10),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eventList.Items, nil
+}
+
+func listNamespaces(ctx context.Context, clientset kubernetes.Interface) ([]string, error) {
+	namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ns := range namespaceList.Items {
+		names = append(names, ns.Name)
+	}
+	return names, nil
+}
+
+// currentContext returns the name of the kubeconfig context in use
+func (o *EventSummaryOptions) currentContext() string {
+	if o.ConfigFlags.Context != nil && *o.ConfigFlags.Context != "" {
+		return *o.ConfigFlags.Context
+	}
+	raw, err := o.ConfigFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return ""
+	}
+	return raw.CurrentContext
+}
+
+// contextNames returns the kubeconfig contexts to fetch events from
+func (o *EventSummaryOptions) contextNames() ([]string, error) {
+	if len(o.Contexts) > 0 {
+		return o.Contexts, nil
+	}
+
+	raw, err := o.ConfigFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	var names []string
+	for name := range raw.Contexts {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no contexts found in kubeconfig")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// configFlagsForContext returns client flags pointing at the given context.
+// Flags that apply to every cluster are carried over; cluster and user
+// overrides are not, since they belong to the context.
+func (o *EventSummaryOptions) configFlagsForContext(name string) *genericclioptions.ConfigFlags {
+	base := o.ConfigFlags
+	flags := genericclioptions.NewConfigFlags(true)
+	flags.Context = ptr.To(name)
+	flags.CacheDir = base.CacheDir
+	flags.KubeConfig = base.KubeConfig
+	flags.Namespace = base.Namespace
+	flags.Impersonate = base.Impersonate
+	flags.ImpersonateUID = base.ImpersonateUID
+	flags.ImpersonateGroup = base.ImpersonateGroup
+	flags.Insecure = base.Insecure
+	flags.DisableCompression = base.DisableCompression
+	flags.Timeout = base.Timeout
+	if flags.Timeout == nil || *flags.Timeout == "" || *flags.Timeout == "0" {
+		flags.Timeout = ptr.To(o.ClusterTimeout.String())
+	}
+	return flags
+}
@@ -0,0 +1,131 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestValidateClusterTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		wantErr string
+	}{
+		{timeout: 30 * time.Second},
+		{timeout: time.Millisecond},
+		{timeout: 0, wantErr: "invalid cluster-timeout: 0s, must be positive"},
+		{timeout: -time.Second, wantErr: "invalid cluster-timeout: -1s, must be positive"},
+	}
+	for _, tt := range tests {
+		o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+		o.Format, o.SortBy, o.MaxConcurrency = "wide", "lastTimestamp", 5
+		o.Contexts = []string{"prod", "staging"}
+		o.ClusterTimeout = tt.timeout
+
+		err := o.Validate()
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.timeout, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: got error %v, want %q", tt.timeout, err, tt.wantErr)
+		}
+	}
+}
+
+// forbidClusterWideEvents makes listing events across all namespaces, and in
+// the given namespaces, forbidden
+func forbidClusterWideEvents(clientset *fake.Clientset, namespaces ...string) {
+	clientset.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		namespace := action.GetNamespace()
+		forbidden := namespace == ""
+		for _, ns := range namespaces {
+			forbidden = forbidden || ns == namespace
+		}
+		if !forbidden {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "events"}, "", nil)
+	})
+}
+
+func TestListEventsPerNamespaceFallback(t *testing.T) {
+	namespace := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	event := func(namespace, name string) *corev1.Event {
+		return &corev1.Event{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+
+	tests := []struct {
+		name          string
+		objects       []runtime.Object
+		forbidden     []string
+		wantEvents    int
+		wantForbidden []string
+		wantErr       string
+	}{
+		{
+			name:       "all namespaces readable",
+			objects:    []runtime.Object{namespace("shop"), namespace("kube-system"), event("shop", "a"), event("kube-system", "b")},
+			wantEvents: 2,
+		},
+		{
+			name:          "some namespaces forbidden",
+			objects:       []runtime.Object{namespace("shop"), namespace("kube-system"), event("shop", "a"), event("kube-system", "b")},
+			forbidden:     []string{"kube-system"},
+			wantEvents:    1,
+			wantForbidden: []string{"kube-system"},
+		},
+		{
+			name:      "every namespace forbidden",
+			objects:   []runtime.Object{namespace("shop")},
+			forbidden: []string{"shop"},
+			wantErr:   "forbidden in all 1 namespaces",
+		},
+		{
+			name:    "no namespaces visible",
+			wantErr: "no namespaces visible for the per-namespace fallback",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tt.objects...)
+			forbidClusterWideEvents(clientset, tt.forbidden...)
+			o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.MaxConcurrency = 2
+
+			items, forbidden, err := o.listEvents(context.Background(), clientset, "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != tt.wantEvents || strings.Join(forbidden, ",") != strings.Join(tt.wantForbidden, ",") {
+				t.Errorf("got %d events and forbidden %v, want %d and %v", len(items), forbidden, tt.wantEvents, tt.wantForbidden)
+			}
+		})
+	}
+
+	// An empty list of namespaces is listed without starting any request
+	o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.MaxConcurrency = 2
+	clientset := fake.NewSimpleClientset()
+	items, forbidden, err := o.listEventsPerNamespace(context.Background(), clientset, nil)
+	if err != nil || len(items) != 0 || len(forbidden) != 0 || len(clientset.Actions()) != 0 {
+		t.Errorf("got %d events, forbidden %v, error %v and %d requests", len(items), forbidden, err, len(clientset.Actions()))
+	}
+}
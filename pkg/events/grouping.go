@@ -8,7 +8,7 @@ import (
 	"github.com/nareshku/kubectl-event-summary/pkg/types"
 )
 
-func buildGroupKey(event corev1.Event, groupLevels []string) string {
+func buildGroupKey(event corev1.Event, cluster string, groupLevels []string) string {
 	var parts []string
 	for _, level := range groupLevels {
 		var value string
@@ -21,6 +21,8 @@ func buildGroupKey(event corev1.Event, groupLevels []string) string {
 			value = event.Reason
 		case "type":
 			value = event.Type
+		case "cluster":
+			value = cluster
 		}
 		parts = append(parts, level+"="+value)
 	}
@@ -38,62 +40,141 @@ func shouldIncludeEvent(event corev1.Event, severity types.Severity) bool {
 	case types.SeverityError:
 		// In Kubernetes events, errors are typically marked as Warning type
 		// but we can check for specific error-like reasons
-		return event.Type == "Warning" && (
-			strings.Contains(strings.ToLower(event.Reason), "error") ||
-			strings.Contains(strings.ToLower(event.Reason), "failed") ||
-			strings.Contains(strings.ToLower(event.Reason), "backoff"))
+		return event.Type == "Warning" && isErrorEvent(event)
 	default:
 		return true
 	}
 }
 
-func groupEvents(events []corev1.Event, groupBy string, filter string, severity types.Severity) (map[string]*types.GroupSummary, []string, error) {
-	groupLevels := strings.Split(groupBy, ",")
-	groups := make(map[string]*types.GroupSummary)
-	
-	// Build groups and collect statistics
-	for _, event := range events {
-		// Check severity filter
-		if !shouldIncludeEvent(event, severity) {
-			continue
+// isErrorEvent reports whether the event's reason looks like an error
+func isErrorEvent(event corev1.Event) bool {
+	return types.IsErrorReason(event.Reason)
+}
+
+// eventGrouper groups events one at a time, so that each event can be
+// streamed as soon as it passes the severity and group filters
+type eventGrouper struct {
+	levels   []string
+	filter   string
+	severity types.Severity
+	// keepEvents stores the events in their groups; streamed events are
+	// only counted
+	keepEvents bool
+	groups     map[string]*types.GroupSummary
+}
+
+// newEventGrouper returns a grouper for the --group-by levels. Without
+// levels, all events go into a single group named after the severity.
+func newEventGrouper(groupBy string, filter string, severity types.Severity) (*eventGrouper, error) {
+	g := &eventGrouper{
+		severity:   severity,
+		keepEvents: true,
+		groups:     make(map[string]*types.GroupSummary),
+	}
+	if groupBy == "" {
+		// Use severity as the key instead of "all"
+		groupKey := string(severity)
+		if groupKey == string(types.SeverityAll) {
+			groupKey = "all events"
+		}
+		g.groups[groupKey] = newGroupSummary()
+		return g, nil
+	}
+
+	g.levels = strings.Split(groupBy, ",")
+	if filter != "" {
+		filterParts := strings.Split(filter, "=")
+		if len(filterParts) != 2 {
+			return nil, fmt.Errorf("invalid filter format. Use 'field=value'")
 		}
+		g.filter = filterParts[0] + "=" + filterParts[1]
+	}
+	return g, nil
+}
+
+func newGroupSummary() *types.GroupSummary {
+	return &types.GroupSummary{
+		Types:   make(map[string]int),
+		Reasons: make(map[string]int),
+	}
+}
+
+// add counts the event, fetched from cluster, in its group and returns the
+// group key, or false if the event is filtered out by severity or group filter
+func (g *eventGrouper) add(event corev1.Event, cluster string) (string, bool) {
+	// Check severity filter
+	if !shouldIncludeEvent(event, g.severity) {
+		return "", false
+	}
 
-		groupKey := buildGroupKey(event, groupLevels)
-		
+	var groupKey string
+	if len(g.levels) == 0 {
+		for key := range g.groups {
+			groupKey = key
+		}
+	} else {
+		groupKey = buildGroupKey(event, cluster, g.levels)
 		// Apply filter if specified
-		if filter != "" {
-			filterParts := strings.Split(filter, "=")
-			if len(filterParts) != 2 {
-				return nil, nil, fmt.Errorf("invalid filter format. Use 'field=value'")
-			}
-			if !strings.HasPrefix(groupKey, filterParts[0]+"="+filterParts[1]) {
-				continue
-			}
+		if g.filter != "" && !strings.HasPrefix(groupKey, g.filter) {
+			return "", false
 		}
+	}
+
+	if g.groups[groupKey] == nil {
+		g.groups[groupKey] = newGroupSummary()
+	}
 
-		if groups[groupKey] == nil {
-			groups[groupKey] = &types.GroupSummary{
-				Types:    make(map[string]int),
-				Reasons:  make(map[string]int),
-			}
+	summary := g.groups[groupKey]
+	summary.Total++
+	if event.Type == "Warning" {
+		summary.Warnings++
+		// Grouped events count errors the same as ungrouped ones, so that
+		// the filtered errors total and --fail-on errors rules do not
+		// depend on --group-by
+		if isErrorEvent(event) {
+			summary.Errors++
 		}
-		
-		summary := groups[groupKey]
-		summary.Total++
-		if event.Type == "Warning" {
-			summary.Warnings++
+	}
+	summary.Types[event.Type]++
+	summary.Reasons[event.Reason]++
+	if g.keepEvents {
+		summary.AddEvent(event, cluster)
+	}
+	return groupKey, true
+}
+
+// remove uncounts an event that add counted in the group groupKey, without
+// removing it from the group's events. Emptied groups are dropped, except
+// the single group of an ungrouped summary.
+func (g *eventGrouper) remove(event corev1.Event, groupKey string) {
+	summary := g.groups[groupKey]
+	if summary == nil {
+		return
+	}
+	summary.Total--
+	if event.Type == "Warning" {
+		summary.Warnings--
+		if isErrorEvent(event) {
+			summary.Errors--
 		}
-		summary.Types[event.Type]++
-		summary.Reasons[event.Reason]++
-		summary.Events = append(summary.Events, event)
 	}
+	if summary.Types[event.Type]--; summary.Types[event.Type] <= 0 {
+		delete(summary.Types, event.Type)
+	}
+	if summary.Reasons[event.Reason]--; summary.Reasons[event.Reason] <= 0 {
+		delete(summary.Reasons, event.Reason)
+	}
+	if summary.Total <= 0 && len(g.levels) > 0 {
+		delete(g.groups, groupKey)
+	}
+}
 
-	// Sort group keys
+// keys returns the sorted group keys
+func (g *eventGrouper) keys() []string {
 	var keys []string
-	for k := range groups {
+	for k := range g.groups {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
-
-	return groups, keys, nil
-} 
\ No newline at end of file
+	return keys
+}
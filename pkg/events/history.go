@@ -0,0 +1,120 @@
+package events
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/store"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// OpenStore opens the local event store, if one is configured. Events
+// stored without a cluster are recorded under the current context.
+func (o *EventSummaryOptions) OpenStore() error {
+	if !o.Store.Enabled() || o.store != nil {
+		return nil
+	}
+	s, err := store.Open(o.Store)
+	if err != nil {
+		return err
+	}
+	o.store = s
+	o.storeCluster = o.currentContext()
+	return nil
+}
+
+// StoreEvents appends the events fetched from cluster, or from the current
+// context if empty, to the local event store, if it is open
+func (o *EventSummaryOptions) StoreEvents(cluster string, events []corev1.Event) error {
+	if o.store == nil {
+		return nil
+	}
+	if cluster == "" {
+		cluster = o.storeCluster
+	}
+	_, err := o.store.Append(cluster, events)
+	return err
+}
+
+// withStoredEvents adds the stored events the API server no longer returns
+// to the clusters, when the window, including any anomaly baseline, reaches
+// back further than EventTTL.
+// Stored events are scoped and narrowed to the targets like fetched ones.
+func (o *EventSummaryOptions) withStoredEvents(clusters []clusterEvents, now time.Time) ([]clusterEvents, error) {
+	if o.store == nil || o.snapshot != nil || o.lookback() <= o.EventTTL {
+		return clusters, nil
+	}
+
+	since := now.Add(-o.lookback())
+	targeted := len(o.ResourceArgs) > 0 || o.Selector != ""
+	for i := range clusters {
+		cluster := &clusters[i]
+		if cluster.Err != nil {
+			continue
+		}
+		stored, err := o.store.Query(cluster.Name, since)
+		if err != nil {
+			return nil, err
+		}
+
+		// Events the API server still returns are already counted, targeted
+		// or not
+		live := make(map[k8stypes.UID]bool, len(cluster.Fetched))
+		for _, event := range cluster.Fetched {
+			live[event.UID] = true
+		}
+		for _, event := range stored {
+			if live[event.UID] || !o.inNamespaceScope(*cluster, event) {
+				continue
+			}
+
+			cluster.Total++
+			if event.Type == "Warning" {
+				cluster.Warnings++
+				if isErrorEvent(event) {
+					cluster.Errors++
+				}
+			}
+
+			if targeted && !targetsMatch(cluster.Targets, event) {
+				continue
+			}
+			cluster.Events = append(cluster.Events, event)
+		}
+	}
+	return clusters, nil
+}
+
+// inNamespaceScope reports whether the event is in a namespace the cluster's
+// events were listed from
+func (o *EventSummaryOptions) inNamespaceScope(cluster clusterEvents, event corev1.Event) bool {
+	if cluster.Namespace != "" {
+		return event.Namespace == cluster.Namespace
+	}
+	if len(o.Namespaces) == 0 {
+		return true
+	}
+	for _, namespace := range o.Namespaces {
+		if event.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// targetsMatch reports whether the event's involved object is one of the
+// resolved targets, by UID or by kind, namespace and name
+func targetsMatch(targets []types.ObjectReference, event corev1.Event) bool {
+	obj := event.InvolvedObject
+	for _, target := range targets {
+		if target.UID != "" && target.UID == string(obj.UID) {
+			return true
+		}
+		if target.Kind == obj.Kind && target.Namespace == obj.Namespace && target.Name == obj.Name {
+			return true
+		}
+	}
+	return false
+}
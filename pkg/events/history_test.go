@@ -0,0 +1,78 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/store"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// storedTestEvent returns a warning about the pod last seen at last
+func storedTestEvent(uid, pod string, last time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: uid, Namespace: "shop", UID: k8stypes.UID(uid), ResourceVersion: "1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: pod},
+		Type:           "Warning",
+		Reason:         "BackOff",
+		LastTimestamp:  metav1.NewTime(last),
+		Count:          1,
+	}
+}
+
+func TestStoredEventsServeOtherTargets(t *testing.T) {
+	now := time.Now()
+	o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+	kubeContext := "prod"
+	o.ConfigFlags.Context = &kubeContext
+	o.Store = store.Options{Dir: t.TempDir(), Retention: 24 * time.Hour}
+	o.Since = 3 * time.Hour
+	o.EventTTL = time.Hour
+	o.ResourceArgs = []string{"pod/web-0"}
+	if err := o.OpenStore(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Watching web-0 stores the events of web-1 as well
+	old := []corev1.Event{
+		storedTestEvent("a", "web-0", now.Add(-2*time.Hour)),
+		storedTestEvent("b", "web-1", now.Add(-2*time.Hour)),
+	}
+	if err := o.StoreEvents("prod", old); err != nil {
+		t.Fatal(err)
+	}
+
+	// The API server no longer returns them, but still returns c of web-1
+	live := storedTestEvent("c", "web-1", now.Add(-time.Minute))
+	if err := o.StoreEvents("prod", []corev1.Event{live}); err != nil {
+		t.Fatal(err)
+	}
+	cluster := clusterEvents{
+		Name:     "prod",
+		Targets:  []types.ObjectReference{{Kind: "Pod", Namespace: "shop", Name: "web-1"}},
+		Events:   []corev1.Event{live},
+		Fetched:  []corev1.Event{live},
+		Total:    1,
+		Warnings: 1,
+		Errors:   1,
+	}
+	clusters, err := o.withStoredEvents([]clusterEvents{cluster}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Later targeting web-1 finds its stored event, and the live event is
+	// counted once
+	got := clusters[0]
+	if len(got.Events) != 2 || got.Events[1].Name != "b" {
+		t.Errorf("got events %v, want c and b", got.Events)
+	}
+	if got.Total != 3 || got.Warnings != 3 {
+		t.Errorf("got %d events and %d warnings in total, want 3 and 3", got.Total, got.Warnings)
+	}
+}
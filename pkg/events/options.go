@@ -2,26 +2,156 @@ package events
 
 import (
     "context"
+    "errors"
     "fmt"
+    "net/url"
+    "os"
+    "os/signal"
     "strings"
+    "syscall"
     "time"
 
     "github.com/spf13/cobra"
     corev1 "k8s.io/api/core/v1"
-    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-    "k8s.io/client-go/kubernetes"
-    "k8s.io/utils/ptr"
 
+    "github.com/nareshku/kubectl-event-summary/pkg/output"
+    "github.com/nareshku/kubectl-event-summary/pkg/snapshot"
     "github.com/nareshku/kubectl-event-summary/pkg/types"
 )
 
 // Complete completes all the required options
 func (o *EventSummaryOptions) Complete(cmd *cobra.Command, args []string) error {
+    o.ResourceArgs = args
+    if o.FromSnapshot != "" {
+        if err := o.loadSnapshot(); err != nil {
+            return err
+        }
+    }
+    if o.BaselineSnapshot != "" {
+        baseline, err := snapshot.Read(o.BaselineSnapshot)
+        if err != nil {
+            return err
+        }
+        o.baseline = baseline
+    }
     return nil
 }
 
 // Validate validates the provided options
 func (o *EventSummaryOptions) Validate() error {
+    if err := o.ValidateSummary(); err != nil {
+        return err
+    }
+
+    if o.Format != "wide" {
+        if _, err := output.NewFormatter(o.Format, o.Out, o.outputOptions()); err != nil {
+            return err
+        }
+    }
+
+    if o.SortBy != "lastTimestamp" && o.SortBy != "count" {
+        return fmt.Errorf("invalid sort-by: %s, must be one of: lastTimestamp, count", o.SortBy)
+    }
+
+    if o.Recursive && len(o.ResourceArgs) == 0 && o.Selector == "" {
+        return fmt.Errorf("--recursive requires a resource argument or --selector")
+    }
+
+    if len(o.Namespaces) > 0 && o.AllNs {
+        return fmt.Errorf("--namespaces and --all-namespaces cannot be used together")
+    }
+
+    if len(o.Namespaces) > 0 && (len(o.ResourceArgs) > 0 || o.Selector != "") {
+        return fmt.Errorf("--namespaces cannot be used together with resource arguments or --selector")
+    }
+
+    if o.ClusterTimeout <= 0 {
+        return fmt.Errorf("invalid cluster-timeout: %s, must be positive", o.ClusterTimeout)
+    }
+
+    if o.MaxConcurrency < 1 {
+        return fmt.Errorf("invalid max-concurrency: %d, must be at least 1", o.MaxConcurrency)
+    }
+
+    if err := o.Notify.Validate(); err != nil {
+        return err
+    }
+
+    if err := o.OTLP.Validate(); err != nil {
+        return err
+    }
+
+    if err := o.Loki.ValidateLoki(); err != nil {
+        return err
+    }
+
+    if err := o.Elasticsearch.ValidateElasticsearch(); err != nil {
+        return err
+    }
+
+    if err := o.CloudEvents.ValidateCloudEvents(); err != nil {
+        return err
+    }
+
+    o.alertRules = nil
+    for _, rule := range o.AlertOn {
+        t, err := parseThreshold("alert-on", rule)
+        if err != nil {
+            return err
+        }
+        o.alertRules = append(o.alertRules, t)
+    }
+    for _, label := range o.AlertLabels {
+        if !validAlertLabel(label) {
+            return fmt.Errorf("invalid alert-label: %q, must be name=value with a valid label name", label)
+        }
+    }
+    if o.AlertmanagerURL != "" {
+        if u, err := url.Parse(o.AlertmanagerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return fmt.Errorf("invalid alertmanager-url: %s, must be an http or https URL", o.AlertmanagerURL)
+        }
+    }
+
+    if o.Anomalies && o.BaselineWindows < 1 {
+        return fmt.Errorf("invalid baseline-windows: %d, must be at least 1", o.BaselineWindows)
+    }
+
+    if o.BaselineSnapshot != "" && !o.Anomalies {
+        return fmt.Errorf("--baseline-snapshot requires --anomalies")
+    }
+
+    if o.Watch && o.WatchInterval <= 0 {
+        return fmt.Errorf("invalid watch-interval: %s, must be positive", o.WatchInterval)
+    }
+
+    if o.AllContexts && len(o.Contexts) > 0 {
+        return fmt.Errorf("--contexts and --all-contexts cannot be used together")
+    }
+
+    if o.FromSnapshot != "" {
+        if o.Watch {
+            return fmt.Errorf("--from-snapshot cannot be used together with --watch")
+        }
+        if o.AllContexts || len(o.Contexts) > 0 || len(o.Namespaces) > 0 || o.AllNs {
+            return fmt.Errorf("--from-snapshot cannot be used together with --contexts, --all-contexts, --namespaces or --all-namespaces; the snapshot holds the events it was recorded with")
+        }
+        if len(o.ResourceArgs) > 0 || o.Selector != "" || o.Recursive {
+            return fmt.Errorf("--from-snapshot cannot be used together with resource arguments, --selector or --recursive; the snapshot holds the events it was recorded with")
+        }
+        return nil
+    }
+
+    if o.multiCluster() && o.ConfigFlags.Context != nil && *o.ConfigFlags.Context != "" {
+        return fmt.Errorf("--context cannot be used together with --contexts or --all-contexts")
+    }
+
+    return nil
+}
+
+// ValidateSummary validates the options that shape the summary of events at
+// hand: the severity, the thresholds, the local store and the event TTL. It
+// is all serve needs, as it watches the events itself.
+func (o *EventSummaryOptions) ValidateSummary() error {
     switch o.Severity {
     case types.SeverityAll, types.SeverityNormal, types.SeverityWarning, types.SeverityError:
         // valid severity
@@ -29,12 +159,21 @@ func (o *EventSummaryOptions) Validate() error {
         return fmt.Errorf("invalid severity: %s, must be one of: all, normal, warning, error", o.Severity)
     }
 
-    if o.Format != "wide" && o.Format != "json" && o.Format != "yaml" {
-        return fmt.Errorf("invalid format: %s, must be one of: wide, json, yaml", o.Format)
+    o.thresholds = nil
+    for _, rule := range o.FailOn {
+        t, err := parseThreshold("fail-on", rule)
+        if err != nil {
+            return err
+        }
+        o.thresholds = append(o.thresholds, t)
     }
 
-    if o.SortBy != "lastTimestamp" && o.SortBy != "count" {
-        return fmt.Errorf("invalid sort-by: %s, must be one of: lastTimestamp, count", o.SortBy)
+    if err := o.Store.Validate(); err != nil {
+        return err
+    }
+
+    if o.EventTTL <= 0 {
+        return fmt.Errorf("invalid event-ttl: %s, must be positive", o.EventTTL)
     }
 
     return nil
@@ -42,217 +181,327 @@ func (o *EventSummaryOptions) Validate() error {
 
 // Run executes the command
 func (o *EventSummaryOptions) Run() error {
-    config, err := o.ConfigFlags.ToRESTConfig()
-    if err != nil {
-        return fmt.Errorf("failed to get client config: %v", err)
+    var formatter output.Formatter
+    if o.Format != "wide" {
+        var err error
+        formatter, err = output.NewFormatter(o.Format, o.Out, o.outputOptions())
+        if err != nil {
+            return err
+        }
     }
 
-    clientset, err := kubernetes.NewForConfig(config)
-    if err != nil {
-        return fmt.Errorf("failed to create clientset: %v", err)
+    if o.snapshot == nil {
+        if err := o.OpenStore(); err != nil {
+            return err
+        }
     }
 
-    var namespace string
-    if !o.AllNs {
-        var explicit bool
-        namespace, explicit, err = o.ConfigFlags.ToRawKubeConfigLoader().Namespace()
+    if o.Anomalies && o.snapshot == nil && !o.Store.Enabled() && o.lookback() > o.EventTTL {
+        fmt.Fprintf(o.ErrOut, "Warning: the anomaly baseline reaches back %s, beyond the %s the API server keeps events; use --store-dir or --baseline-snapshot for a complete baseline\n",
+            o.lookback(), o.EventTTL)
+    }
+
+    if o.Watch {
+        return o.watch(formatter)
+    }
+    return o.runOnce(context.TODO(), formatter)
+}
+
+// watch repeats the summary every WatchInterval until interrupted. Events
+// already streamed are not written again, and errors after the first
+// summary, including breached thresholds, are reported without stopping.
+func (o *EventSummaryOptions) watch(formatter output.Formatter) error {
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    o.streamed = make(map[string]bool)
+    ticker := time.NewTicker(o.WatchInterval)
+    defer ticker.Stop()
+    for first := true; ; first = false {
+        err := o.runOnce(ctx, formatter)
+        var thresholdErr *ThresholdError
+        if err != nil && first && !errors.As(err, &thresholdErr) {
+            return err
+        }
         if err != nil {
-            return fmt.Errorf("failed to get namespace: %v", err)
+            fmt.Fprintf(o.ErrOut, "Warning: %v\n", err)
         }
-        if !explicit {
-            fmt.Fprintf(o.ErrOut, "Using namespace %q\n", namespace)
+
+        select {
+        case <-ctx.Done():
+            return nil
+        case <-ticker.C:
         }
     }
+}
 
-    eventList, err := clientset.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{
-        TimeoutSeconds: ptr.To[int64](10),
-    })
+// runOnce fetches, summarizes and prints the events once. Formatters
+// implementing output.EventStreamer receive each event as it passes the
+// filters instead of the events being kept in the summary.
+func (o *EventSummaryOptions) runOnce(ctx context.Context, formatter output.Formatter) error {
+    clusters, now, err := o.loadEvents(ctx)
     if err != nil {
-        return fmt.Errorf("failed to list events: %v", err)
+        return err
     }
 
-    // First, count total events and warnings/errors before filtering
-    totalInitialEvents := len(eventList.Items)
-    totalInitialWarnings := 0
-    totalInitialErrors := 0
-    for _, event := range eventList.Items {
-        if event.Type == "Warning" {
-            totalInitialWarnings++
-            if strings.Contains(strings.ToLower(event.Reason), "error") ||
-               strings.Contains(strings.ToLower(event.Reason), "failed") ||
-               strings.Contains(strings.ToLower(event.Reason), "backoff") {
-                totalInitialErrors++
+    // Keep the events seen in watch mode beyond the API server's event TTL,
+    // all of them, so that the history serves any targets later
+    if o.Watch && o.snapshot == nil {
+        for _, cluster := range clusters {
+            if err := o.StoreEvents(cluster.Name, cluster.Fetched); err != nil {
+                return err
             }
         }
     }
+    clusters, err = o.withStoredEvents(clusters, now)
+    if err != nil {
+        return err
+    }
 
-    // Filter events by time window and search string
-    var filteredEvents []corev1.Event
-    timeWindow := time.Now().Add(-o.Since)
-    for _, event := range eventList.Items {
-        // Use EventTime if available, otherwise use LastTimestamp, then FirstTimestamp
-        eventTime := event.EventTime.Time
-        if eventTime.IsZero() {
-            eventTime = event.LastTimestamp.Time
+    for _, cluster := range clusters {
+        if len(cluster.Forbidden) == 0 {
+            continue
         }
-        if eventTime.IsZero() {
-            eventTime = event.FirstTimestamp.Time
+        where := ""
+        if o.multiCluster() {
+            where = fmt.Sprintf(" of cluster %s", cluster.Name)
         }
+        fmt.Fprintf(o.ErrOut, "Warning: skipped namespaces%s where listing events is forbidden: %s\n",
+            where, strings.Join(cluster.Forbidden, ", "))
+    }
 
-        // Include events that happened at or after the time window
-        if eventTime.Before(timeWindow) {
-            continue
-        }
+    var stream streamFunc
+    if streamer, ok := formatter.(output.EventStreamer); ok {
+        stream = o.dedupeStream(streamer.StreamEvent)
+    }
 
-        // Apply search filter if specified
-        if o.Search != "" {
-            searchLower := strings.ToLower(o.Search)
-            // Check various fields for the search string
-            if !strings.Contains(strings.ToLower(event.InvolvedObject.Name), searchLower) &&
-               !strings.Contains(strings.ToLower(event.Message), searchLower) &&
-               !strings.Contains(strings.ToLower(event.Reason), searchLower) &&
-               !strings.Contains(strings.ToLower(event.InvolvedObject.Namespace), searchLower) &&
-               !strings.Contains(strings.ToLower(event.InvolvedObject.Kind), searchLower) {
-                continue
-            }
-        }
+    summary, matched, err := o.summarize(clusters, now, stream)
+    if err != nil {
+        return err
+    }
 
-        filteredEvents = append(filteredEvents, event)
+    if o.Anomalies {
+        summary.Anomalies, err = o.detectAnomalies(summary, clusters, now)
+        if err != nil {
+            return err
+        }
     }
 
-    // If no events found after filtering, show a message with total events
-    if len(filteredEvents) == 0 {
+    // If no events found after filtering, show a message with total events.
+    // Structured formats still print an (empty) summary document.
+    if matched == 0 && formatter == nil {
         fmt.Fprintf(o.Out, "\nTotal Events in cluster: %d (Warnings: %d, Errors: %d)\n", 
-            totalInitialEvents, 
-            totalInitialWarnings,
-            totalInitialErrors)
+            summary.Totals.Total, 
+            summary.Totals.Warnings,
+            summary.Totals.Errors)
+        o.printClusterTotals(summary.Clusters)
         if o.Search != "" {
             fmt.Fprintf(o.Out, "No events found matching search term: %q\n", o.Search)
         } else {
             fmt.Fprintf(o.Out, "No events found matching the specified criteria\n")
         }
-        return nil
+        o.printProblems(summary.Problems)
+        o.printAnomalies(summary.Anomalies)
+    } else {
+        printed := summary
+        if stream != nil {
+            printed = summary.WithoutEvents()
+        }
+        if err := o.printSummary(formatter, printed); err != nil {
+            return err
+        }
     }
 
-    // Group events if grouping is requested
-    var groups map[string]*types.GroupSummary
-    var keys []string
-    if o.GroupBy != "" {
-        groups, keys, err = groupEvents(filteredEvents, o.GroupBy, o.Filter, o.Severity)
-        if err != nil {
+    if o.Notify.Enabled() {
+        if err := o.Notify.Notify(ctx, o.Out, summary); err != nil {
             return err
         }
-        // Add initial totals to all groups
-        for _, summary := range groups {
-            summary.InitialTotal = totalInitialEvents
-            summary.InitialWarnings = totalInitialWarnings
-            summary.InitialErrors = totalInitialErrors
+    }
+
+    if o.AlertmanagerURL != "" {
+        if err := o.sendAlerts(ctx, summary); err != nil {
+            return err
         }
-    } else {
-        // Create a single group for all events
-        // First filter events by severity
-        var severityFilteredEvents []corev1.Event
-        for _, event := range filteredEvents {
-            if shouldIncludeEvent(event, o.Severity) {
-                severityFilteredEvents = append(severityFilteredEvents, event)
+    }
+
+    if o.exportsLogs() {
+        if err := o.exportLogs(ctx, summary); err != nil {
+            return err
+        }
+    }
+
+    return o.checkThresholds(summary)
+}
+
+// streamFunc receives each event that passes the filters with its cluster
+// and group key
+type streamFunc func(event corev1.Event, cluster, groupKey string) error
+
+// dedupeStream skips events streamed by an earlier summary of the watch,
+// identified by UID and resourceVersion. Outside watch mode the stream is
+// returned as is.
+func (o *EventSummaryOptions) dedupeStream(stream streamFunc) streamFunc {
+    if o.streamed == nil {
+        return stream
+    }
+
+    previous := o.streamed
+    o.streamed = make(map[string]bool)
+    return func(event corev1.Event, cluster, groupKey string) error {
+        id := string(event.UID) + "/" + event.ResourceVersion
+        o.streamed[id] = true
+        if previous[id] {
+            return nil
+        }
+        return stream(event, cluster, groupKey)
+    }
+}
+
+// Summarize filters and groups events of a single cluster as of now, the
+// same way Run does, adding events from the local store if it is open
+func (o *EventSummaryOptions) Summarize(events []corev1.Event, now time.Time) (*types.Summary, error) {
+    cluster := clusterEvents{Events: events, Fetched: events}
+    for _, event := range events {
+        cluster.Total++
+        if event.Type == "Warning" {
+            cluster.Warnings++
+            if isErrorEvent(event) {
+                cluster.Errors++
             }
         }
-        
-        // Use severity as the key instead of "all"
-        groupKey := string(o.Severity)
-        if groupKey == string(types.SeverityAll) {
-            groupKey = "all events"
+    }
+    if o.store != nil {
+        // Scope the stored events like the given ones
+        cluster.Name = o.storeCluster
+        if !o.AllNs {
+            cluster.Namespace, _, _ = o.ConfigFlags.ToRawKubeConfigLoader().Namespace()
         }
-        
-        groups = map[string]*types.GroupSummary{
-            groupKey: {
-                Types:           make(map[string]int),
-                Reasons:         make(map[string]int),
-                Events:         severityFilteredEvents,
-                InitialTotal:   totalInitialEvents,
-                InitialWarnings: totalInitialWarnings,
-                InitialErrors:  totalInitialErrors,
-            },
-        }
-        keys = []string{groupKey}
-        
-        // Update the summary counts
-        summary := groups[groupKey]
-        for _, event := range severityFilteredEvents {
-            summary.Total++
-            if event.Type == "Warning" {
-                summary.Warnings++
-                // Count errors based on reason
-                if strings.Contains(strings.ToLower(event.Reason), "error") ||
-                   strings.Contains(strings.ToLower(event.Reason), "failed") ||
-                   strings.Contains(strings.ToLower(event.Reason), "backoff") {
-                    summary.Errors++
-                }
+    }
+    clusters, err := o.withStoredEvents([]clusterEvents{cluster}, now)
+    if err != nil {
+        return nil, err
+    }
+    summary, _, err := o.summarize(clusters, now, nil)
+    return summary, err
+}
+
+// summarize filters the fetched events by time window, search string and
+// severity and groups them. Events are passed to stream, if set, as soon
+// as they pass the filters and are then left out of the groups. It also
+// returns the number of events left after the time window and search
+// filters.
+func (o *EventSummaryOptions) summarize(clusters []clusterEvents, now time.Time, stream streamFunc) (*types.Summary, int, error) {
+    grouper, err := newEventGrouper(o.GroupBy, o.Filter, o.Severity)
+    if err != nil {
+        return nil, 0, err
+    }
+    // Exporters need the events even when they are streamed
+    grouper.keepEvents = stream == nil || o.exportsEvents()
+    var problems *problemFinder
+    if o.Problems {
+        problems = newProblemFinder(clusters)
+    }
+
+    // Filter events by time window and search string, then group them
+    matched := 0
+    timeWindow := now.Add(-o.Since)
+    for _, cluster := range clusters {
+        for _, event := range cluster.Events {
+            // Include events that happened at or after the time window
+            if types.EventTime(event).Before(timeWindow) {
+                continue
+            }
+
+            // Apply search filter if specified
+            if !matchesSearch(event, o.Search) {
+                continue
+            }
+            matched++
+            if problems != nil {
+                problems.add(event, cluster.Name)
+            }
+
+            groupKey, ok := grouper.add(event, cluster.Name)
+            if !ok || stream == nil {
+                continue
+            }
+            if err := stream(event, cluster.Name, groupKey); err != nil {
+                return nil, 0, err
             }
-            summary.Types[event.Type]++
-            summary.Reasons[event.Reason]++
         }
     }
 
-    // Format and display events based on output format
-    switch o.Format {
-    case "wide":
-        return o.printWideFormat(groups, keys)
-    case "json":
-        return o.printJSONFormat(groups, keys)
-    case "yaml":
-        return o.printYAMLFormat(groups, keys)
-    default:
-        return fmt.Errorf("unsupported format: %s", o.Format)
+    summary := o.buildSummary(grouper.groups, grouper.keys(), clusters, timeWindow, now)
+    summary.Thresholds = o.evaluateThresholds(summary)
+    if problems != nil {
+        summary.Problems = problems.list()
     }
+    return summary, matched, nil
 }
 
-// Helper functions for different output formats
-func (o *EventSummaryOptions) printWideFormat(groups map[string]*types.GroupSummary, keys []string) error {
-    // Get initial totals from any group (they're all the same)
-    var initialTotals *types.GroupSummary
-    for _, summary := range groups {
-        initialTotals = summary
-        break
+// matchesSearch reports whether the search string, if any, is found in the
+// event's involved object name, message, reason, namespace or kind
+func matchesSearch(event corev1.Event, search string) bool {
+    if search == "" {
+        return true
+    }
+    searchLower := strings.ToLower(search)
+    // Check various fields for the search string
+    return strings.Contains(strings.ToLower(event.InvolvedObject.Name), searchLower) ||
+        strings.Contains(strings.ToLower(event.Message), searchLower) ||
+        strings.Contains(strings.ToLower(event.Reason), searchLower) ||
+        strings.Contains(strings.ToLower(event.InvolvedObject.Namespace), searchLower) ||
+        strings.Contains(strings.ToLower(event.InvolvedObject.Kind), searchLower)
+}
+
+// printSummary formats and displays events with the formatter, or in the
+// wide format if it is nil
+func (o *EventSummaryOptions) printSummary(formatter output.Formatter, summary *types.Summary) error {
+    if formatter == nil {
+        return o.printWideFormat(summary)
     }
+    return formatter.Format(summary)
+}
 
-    // Calculate totals for filtered events
-    totalFilteredEvents := 0
-    totalFilteredWarnings := 0
-    totalFilteredErrors := 0
-    
-    for _, summary := range groups {
-        totalFilteredEvents += summary.Total
-        totalFilteredWarnings += summary.Warnings
-        totalFilteredErrors += summary.Errors
+// outputOptions returns the options for formatters in the output package
+func (o *EventSummaryOptions) outputOptions() output.Options {
+    return output.Options{
+        GroupBy:   o.GroupBy,
+        Compact:   o.Compact,
+        NoHeaders: o.NoHeaders,
+        Width:     output.TerminalWidth(o.Out),
+        ESIndex:   o.ESIndex,
     }
+}
 
+// Helper functions for different output formats
+func (o *EventSummaryOptions) printWideFormat(summary *types.Summary) error {
     // Print overall cluster events summary first
     fmt.Fprintf(o.Out, "\nTotal Events in cluster: %d (Warnings: %d, Errors: %d)\n", 
-        initialTotals.InitialTotal, 
-        initialTotals.InitialWarnings,
-        initialTotals.InitialErrors)
+        summary.Totals.Total, 
+        summary.Totals.Warnings,
+        summary.Totals.Errors)
+    o.printClusterTotals(summary.Clusters)
 
     // Always show filtered events summary when using severity filter or grouping
     if o.Severity != types.SeverityAll || o.GroupBy != "" {
         fmt.Fprintf(o.Out, "Filtered Events: %d (Warnings: %d, Errors: %d)\n", 
-            totalFilteredEvents, 
-            totalFilteredWarnings, 
-            totalFilteredErrors)
+            summary.Filtered.Total, 
+            summary.Filtered.Warnings, 
+            summary.Filtered.Errors)
     }
     fmt.Fprintln(o.Out, "---")
 
     // Print group details
-    for _, key := range keys {
-        summary := groups[key]
-        fmt.Fprintf(o.Out, "\n=== %s ===\n", key)
+    for _, group := range summary.Groups {
+        fmt.Fprintf(o.Out, "\n=== %s ===\n", group.Key)
         fmt.Fprintf(o.Out, "Events in group: %d (Warnings: %d, Errors: %d)\n", 
-            summary.Total, 
-            summary.Warnings, 
-            summary.Errors)
+            group.Total, 
+            group.Warnings, 
+            group.Errors)
         
         if !o.Compact {
-            for _, event := range summary.Events {
+            for _, event := range group.Events {
                 fmt.Fprintf(o.Out, "[%s] %s/%s: %s (count: %d)\n",
                     event.Type,
                     event.InvolvedObject.Namespace,
@@ -262,15 +511,85 @@ func (o *EventSummaryOptions) printWideFormat(groups map[string]*types.GroupSumm
             }
         }
     }
+    o.printProblems(summary.Problems)
+    o.printAnomalies(summary.Anomalies)
     return nil
 }
 
-func (o *EventSummaryOptions) printJSONFormat(groups map[string]*types.GroupSummary, keys []string) error {
-    // TODO: Implement JSON output
-    return fmt.Errorf("JSON output not implemented yet")
+// printProblems prints the failure patterns found, with a remediation hint
+func (o *EventSummaryOptions) printProblems(problems []types.Problem) {
+    if !o.Problems {
+        return
+    }
+    fmt.Fprintln(o.Out, "\n=== Problems ===")
+    if len(problems) == 0 {
+        fmt.Fprintln(o.Out, "No known failure patterns found")
+        return
+    }
+    for _, problem := range problems {
+        where := ""
+        if o.multiCluster() {
+            where = fmt.Sprintf(" (cluster %s)", problem.Cluster)
+        }
+        fmt.Fprintf(o.Out, "[%s] %s %s/%s%s: %d occurrences over %s (%s)\n",
+            problem.Type,
+            problem.Workload.Kind,
+            problem.Workload.Namespace,
+            problem.Workload.Name,
+            where,
+            problem.Count,
+            problem.Duration.Duration,
+            strings.Join(problem.Objects, ", "))
+        fmt.Fprintf(o.Out, "  Last: %s\n", strings.ReplaceAll(problem.Message, "\n", " "))
+        fmt.Fprintf(o.Out, "  Hint: %s\n", problem.Hint)
+    }
+}
+
+// printAnomalies prints the anomalous groups, most abnormal first
+func (o *EventSummaryOptions) printAnomalies(anomalies []types.Anomaly) {
+    if !o.Anomalies {
+        return
+    }
+    if o.baseline != nil {
+        fmt.Fprintf(o.Out, "\n=== Anomalies (vs %d %s windows of %s) ===\n", o.BaselineWindows, o.Since, o.BaselineSnapshot)
+    } else {
+        fmt.Fprintf(o.Out, "\n=== Anomalies (vs %d previous %s windows) ===\n", o.BaselineWindows, o.Since)
+    }
+    if len(anomalies) == 0 {
+        fmt.Fprintln(o.Out, "No group deviates from its baseline")
+        return
+    }
+    for _, anomaly := range anomalies {
+        change := "new"
+        if !anomaly.New {
+            change = fmt.Sprintf("%.1fx", anomaly.Ratio)
+        }
+        fmt.Fprintf(o.Out, "%s: %d (baseline %.1f ± %.1f, z=%+.1f, %s)\n",
+            anomaly.Key,
+            anomaly.Current,
+            anomaly.BaselineMean,
+            anomaly.BaselineStdDev,
+            anomaly.ZScore,
+            change)
+    }
 }
 
-func (o *EventSummaryOptions) printYAMLFormat(groups map[string]*types.GroupSummary, keys []string) error {
-    // TODO: Implement YAML output
-    return fmt.Errorf("YAML output not implemented yet")
-}
\ No newline at end of file
+// printClusterTotals prints a totals row per cluster and the clusters that
+// could not be reached in multi-cluster mode
+func (o *EventSummaryOptions) printClusterTotals(clusters []types.ClusterTotals) {
+    var unreachable []string
+    for _, cluster := range clusters {
+        if cluster.Error != "" {
+            unreachable = append(unreachable, fmt.Sprintf("%s (%s)", cluster.Name, cluster.Error))
+            continue
+        }
+        fmt.Fprintf(o.Out, "  %s: %d (Warnings: %d, Errors: %d)\n",
+            cluster.Name,
+            cluster.Total,
+            cluster.Warnings,
+            cluster.Errors)
+    }
+    if len(unreachable) > 0 {
+        fmt.Fprintf(o.Out, "Unreachable clusters: %s\n", strings.Join(unreachable, ", "))
+    }
+}
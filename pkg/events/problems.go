@@ -0,0 +1,263 @@
+package events
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// problemDetector recognizes a known failure pattern from single events
+type problemDetector struct {
+	Type    string
+	Hint    string
+	Matches func(event corev1.Event) bool
+}
+
+// problemDetectors are tried in order; an event counts towards the first
+// detector it matches
+var problemDetectors = []problemDetector{
+	{
+		Type: "CrashLoopBackOff",
+		Hint: "Check the logs of the previous container run (kubectl logs --previous) and its exit code",
+		Matches: func(event corev1.Event) bool {
+			return event.Reason == "CrashLoopBackOff" ||
+				(event.Reason == "BackOff" && strings.Contains(event.Message, "restarting failed container"))
+		},
+	},
+	{
+		Type: "OOMKilled",
+		Hint: "Raise the container memory limit or reduce its memory use",
+		Matches: func(event corev1.Event) bool {
+			return event.Reason == "OOMKilled" || event.Reason == "OOMKilling" ||
+				strings.Contains(event.Message, "OOMKilled")
+		},
+	},
+	{
+		Type: "ImagePullFailure",
+		Hint: "Check the image name and tag, registry reachability and imagePullSecrets",
+		Matches: func(event corev1.Event) bool {
+			switch event.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "ErrImageNeverPull", "InvalidImageName":
+				return true
+			case "Failed", "BackOff":
+				return strings.Contains(event.Message, "ErrImagePull") ||
+					strings.Contains(event.Message, "ImagePullBackOff") ||
+					strings.Contains(event.Message, "Failed to pull image") ||
+					strings.Contains(event.Message, "Back-off pulling image")
+			}
+			return false
+		},
+	},
+	{
+		Type: "InsufficientResources",
+		Hint: "Lower the pod's resource requests, or add nodes or enable cluster autoscaling",
+		Matches: func(event corev1.Event) bool {
+			return event.Reason == "FailedScheduling" && strings.Contains(event.Message, "Insufficient")
+		},
+	},
+	{
+		Type: "VolumeFailure",
+		Hint: "Check that the PVC is bound, the volume is not attached to another node, and the CSI driver is healthy",
+		Matches: func(event corev1.Event) bool {
+			switch event.Reason {
+			case "FailedMount", "FailedAttachVolume", "FailedMapVolume":
+				return true
+			}
+			return false
+		},
+	},
+	{
+		Type: "ProbeFailure",
+		Hint: "Check the probe path, port and timeouts, and whether the app starts slower than initialDelaySeconds",
+		Matches: func(event corev1.Event) bool {
+			return event.Reason == "Unhealthy" && strings.Contains(strings.ToLower(event.Message), "probe failed")
+		},
+	},
+	{
+		Type: "Eviction",
+		Hint: "Check node memory and disk pressure, and set requests so pods aren't evicted first",
+		Matches: func(event corev1.Event) bool {
+			switch event.Reason {
+			case "Evicted", "Evicting", "EvictionThresholdMet", "Preempted":
+				return true
+			}
+			return false
+		},
+	},
+}
+
+// matchProblem returns the first detector matching the event, or nil
+func matchProblem(event corev1.Event) *problemDetector {
+	for i := range problemDetectors {
+		if problemDetectors[i].Matches(event) {
+			return &problemDetectors[i]
+		}
+	}
+	return nil
+}
+
+// resolveWorkloads returns the workload controlling each Pod involved in a
+// problem event, following its ownerReferences through ReplicaSets to
+// Deployments and through Jobs to CronJobs. Pods that can't be read, e.g.
+// because they were deleted, and Pods without a controller are left out.
+func resolveWorkloads(ctx context.Context, clientset kubernetes.Interface, events []corev1.Event) map[objectRef]types.ObjectReference {
+	workloads := make(map[objectRef]types.ObjectReference)
+	seen := make(map[objectRef]bool)
+	controllers := make(map[k8stypes.UID]types.ObjectReference)
+	for _, event := range events {
+		obj := event.InvolvedObject
+		ref := objectRef{Kind: obj.Kind, Namespace: obj.Namespace, Name: obj.Name}
+		if obj.Kind != "Pod" || seen[ref] || matchProblem(event) == nil {
+			continue
+		}
+		seen[ref] = true
+
+		pod, err := clientset.CoreV1().Pods(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		owner := metav1.GetControllerOfNoCopy(pod)
+		if owner == nil {
+			continue
+		}
+		workload, ok := controllers[owner.UID]
+		if !ok {
+			workload = controllerWorkload(ctx, clientset, obj.Namespace, owner)
+			controllers[owner.UID] = workload
+		}
+		workloads[ref] = workload
+	}
+	return workloads
+}
+
+// controllerWorkload returns the workload at the top of the controller
+// chain starting at owner: the Deployment of a ReplicaSet and the CronJob
+// of a Job, if they have one, and owner itself otherwise
+func controllerWorkload(ctx context.Context, clientset kubernetes.Interface, namespace string, owner *metav1.OwnerReference) types.ObjectReference {
+	workload := types.ObjectReference{Kind: owner.Kind, Namespace: namespace, Name: owner.Name}
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return workload
+	}
+
+	var parent metav1.Object
+	switch {
+	case gv.Group == "apps" && owner.Kind == "ReplicaSet":
+		parent, err = clientset.AppsV1().ReplicaSets(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	case gv.Group == "batch" && owner.Kind == "Job":
+		parent, err = clientset.BatchV1().Jobs(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	default:
+		return workload
+	}
+	if err != nil {
+		return workload
+	}
+	if top := metav1.GetControllerOf(parent); top != nil {
+		workload.Kind, workload.Name = top.Kind, top.Name
+	}
+	return workload
+}
+
+// problemKey identifies a problem by pattern, cluster and workload
+type problemKey struct {
+	Type     string
+	Cluster  string
+	Workload types.ObjectReference
+}
+
+// problemFinder aggregates the events matching a problem detector by
+// pattern and workload
+type problemFinder struct {
+	problems map[problemKey]*types.Problem
+	objects  map[problemKey]map[string]bool
+	// owners holds the resolved workloads of each cluster's Pods
+	owners map[string]map[objectRef]types.ObjectReference
+}
+
+func newProblemFinder(clusters []clusterEvents) *problemFinder {
+	f := &problemFinder{
+		problems: make(map[problemKey]*types.Problem),
+		objects:  make(map[problemKey]map[string]bool),
+		owners:   make(map[string]map[objectRef]types.ObjectReference),
+	}
+	for _, cluster := range clusters {
+		f.owners[cluster.Name] = cluster.Owners
+	}
+	return f
+}
+
+// workloadOf returns the workload controlling the involved object, or the
+// object itself if its controller is unknown
+func (f *problemFinder) workloadOf(obj corev1.ObjectReference, cluster string) types.ObjectReference {
+	if workload, ok := f.owners[cluster][objectRef{Kind: obj.Kind, Namespace: obj.Namespace, Name: obj.Name}]; ok {
+		return workload
+	}
+	return types.ObjectReference{Kind: obj.Kind, Namespace: obj.Namespace, Name: obj.Name}
+}
+
+// add counts the event, fetched from cluster, towards the first problem it
+// matches, if any
+func (f *problemFinder) add(event corev1.Event, cluster string) {
+	detector := matchProblem(event)
+	if detector == nil {
+		return
+	}
+
+	key := problemKey{Type: detector.Type, Cluster: cluster, Workload: f.workloadOf(event.InvolvedObject, cluster)}
+	problem, ok := f.problems[key]
+	if !ok {
+		problem = &types.Problem{Type: detector.Type, Cluster: key.Cluster, Workload: key.Workload, Hint: detector.Hint}
+		f.problems[key] = problem
+		f.objects[key] = make(map[string]bool)
+	}
+	f.objects[key][event.InvolvedObject.Name] = true
+
+	count := int(event.Count)
+	if count < 1 {
+		count = 1
+	}
+	problem.Count += count
+
+	first, last := firstSeen(event), types.EventTime(event)
+	if problem.FirstSeen.IsZero() || first.Before(problem.FirstSeen) {
+		problem.FirstSeen = first
+	}
+	if !last.Before(problem.LastSeen) {
+		problem.LastSeen = last
+		problem.Message = event.Message
+	}
+}
+
+// list returns the problems, most frequent first
+func (f *problemFinder) list() []types.Problem {
+	problems := make([]types.Problem, 0, len(f.problems))
+	for key, problem := range f.problems {
+		for name := range f.objects[key] {
+			problem.Objects = append(problem.Objects, name)
+		}
+		sort.Strings(problem.Objects)
+		problem.Duration = metav1.Duration{Duration: problem.LastSeen.Sub(problem.FirstSeen).Round(time.Second)}
+		problems = append(problems, *problem)
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Workload.Namespace+"/"+a.Workload.Name < b.Workload.Namespace+"/"+b.Workload.Name
+	})
+	return problems
+}
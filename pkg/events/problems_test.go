@@ -0,0 +1,204 @@
+package events
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+func TestMatchProblem(t *testing.T) {
+	tests := []struct {
+		reason  string
+		message string
+		want    string
+	}{
+		{"CrashLoopBackOff", "", "CrashLoopBackOff"},
+		{"BackOff", "Back-off restarting failed container app in pod web-0", "CrashLoopBackOff"},
+		{"OOMKilled", "", "OOMKilled"},
+		{"OOMKilling", "Memory cgroup out of memory: Killed process 4242", "OOMKilled"},
+		{"Killing", "Container app was OOMKilled", "OOMKilled"},
+		{"ErrImagePull", "", "ImagePullFailure"},
+		{"ImagePullBackOff", "", "ImagePullFailure"},
+		{"ErrImageNeverPull", "", "ImagePullFailure"},
+		{"InvalidImageName", "", "ImagePullFailure"},
+		{"Failed", "Failed to pull image \"web:broken\": not found", "ImagePullFailure"},
+		{"Failed", "Error: ErrImagePull", "ImagePullFailure"},
+		{"BackOff", "Back-off pulling image \"web:broken\"", "ImagePullFailure"},
+		{"FailedScheduling", "0/3 nodes are available: 3 Insufficient memory.", "InsufficientResources"},
+		{"FailedMount", "MountVolume.SetUp failed for volume \"data\"", "VolumeFailure"},
+		{"FailedAttachVolume", "Multi-Attach error for volume \"data\"", "VolumeFailure"},
+		{"FailedMapVolume", "", "VolumeFailure"},
+		{"Unhealthy", "Readiness probe failed: HTTP probe failed with statuscode: 503", "ProbeFailure"},
+		{"Unhealthy", "Liveness probe failed: connection refused", "ProbeFailure"},
+		{"Evicted", "The node was low on resource: memory.", "Eviction"},
+		{"Evicting", "", "Eviction"},
+		{"EvictionThresholdMet", "Attempting to reclaim memory", "Eviction"},
+		{"Preempted", "", "Eviction"},
+		// Not a known problem
+		{"FailedScheduling", "0/3 nodes are available: 3 node(s) had untolerated taint", ""},
+		{"BackOff", "Back-off 10s restarting", ""},
+		{"Failed", "Error: container create failed", ""},
+		{"Unhealthy", "", ""},
+		{"Scheduled", "Successfully assigned default/web-0 to node-1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason+"/"+tt.message, func(t *testing.T) {
+			var got string
+			if detector := matchProblem(corev1.Event{Reason: tt.reason, Message: tt.message}); detector != nil {
+				got = detector.Type
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// controlledBy returns object metadata with a controller owner reference
+func controlledBy(name, apiVersion, kind, owner string) metav1.ObjectMeta {
+	objMeta := metav1.ObjectMeta{Name: name, Namespace: "shop", UID: k8stypes.UID("uid-" + name)}
+	if owner != "" {
+		objMeta.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: apiVersion, Kind: kind, Name: owner, UID: k8stypes.UID("uid-" + owner), Controller: ptr.To(true),
+		}}
+	}
+	return objMeta
+}
+
+func problemEvent(kind, name string) corev1.Event {
+	return corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: "shop", Name: name},
+		Reason:         "CrashLoopBackOff",
+		Type:           corev1.EventTypeWarning,
+		Count:          1,
+	}
+}
+
+func TestResolveWorkloads(t *testing.T) {
+	objects := []runtime.Object{
+		&corev1.Pod{ObjectMeta: controlledBy("web-7d4b9c-x2x4z", "apps/v1", "ReplicaSet", "web-7d4b9c")},
+		&appsv1.ReplicaSet{ObjectMeta: controlledBy("web-7d4b9c", "apps/v1", "Deployment", "web")},
+		&corev1.Pod{ObjectMeta: controlledBy("orphan-5f6c7d-q8w9e", "apps/v1", "ReplicaSet", "orphan-5f6c7d")},
+		&appsv1.ReplicaSet{ObjectMeta: controlledBy("orphan-5f6c7d", "", "", "")},
+		&corev1.Pod{ObjectMeta: controlledBy("db-0", "apps/v1", "StatefulSet", "db")},
+		&corev1.Pod{ObjectMeta: controlledBy("logs-abcde", "apps/v1", "DaemonSet", "logs")},
+		&corev1.Pod{ObjectMeta: controlledBy("backup-28512345-k7h2p", "batch/v1", "Job", "backup-28512345")},
+		&batchv1.Job{ObjectMeta: controlledBy("backup-28512345", "batch/v1", "CronJob", "backup")},
+		&corev1.Pod{ObjectMeta: controlledBy("migrate-4kx9z", "batch/v1", "Job", "migrate")},
+		&batchv1.Job{ObjectMeta: controlledBy("migrate", "", "", "")},
+		// Named like Deployment and StatefulSet pods, but without owners
+		&corev1.Pod{ObjectMeta: controlledBy("api-7d4b9c5f6d-x2x4z", "", "", "")},
+		&corev1.Pod{ObjectMeta: controlledBy("worker-1", "", "", "")},
+	}
+	clientset := fake.NewSimpleClientset(objects...)
+
+	tests := []struct {
+		obj  corev1.Event
+		want *types.ObjectReference
+	}{
+		{problemEvent("Pod", "web-7d4b9c-x2x4z"), &types.ObjectReference{Kind: "Deployment", Namespace: "shop", Name: "web"}},
+		{problemEvent("Pod", "orphan-5f6c7d-q8w9e"), &types.ObjectReference{Kind: "ReplicaSet", Namespace: "shop", Name: "orphan-5f6c7d"}},
+		{problemEvent("Pod", "db-0"), &types.ObjectReference{Kind: "StatefulSet", Namespace: "shop", Name: "db"}},
+		{problemEvent("Pod", "logs-abcde"), &types.ObjectReference{Kind: "DaemonSet", Namespace: "shop", Name: "logs"}},
+		{problemEvent("Pod", "backup-28512345-k7h2p"), &types.ObjectReference{Kind: "CronJob", Namespace: "shop", Name: "backup"}},
+		{problemEvent("Pod", "migrate-4kx9z"), &types.ObjectReference{Kind: "Job", Namespace: "shop", Name: "migrate"}},
+		{problemEvent("Pod", "api-7d4b9c5f6d-x2x4z"), nil},
+		{problemEvent("Pod", "worker-1"), nil},
+		// Deleted
+		{problemEvent("Pod", "web-7d4b9c-gone1"), nil},
+		{problemEvent("Node", "node-1"), nil},
+	}
+
+	var events []corev1.Event
+	for _, tt := range tests {
+		events = append(events, tt.obj)
+	}
+	// Not a problem, so not resolved
+	unrelated := problemEvent("Pod", "db-0")
+	unrelated.Reason = "Scheduled"
+	unrelated.InvolvedObject.Namespace = "other"
+	events = append(events, unrelated)
+
+	workloads := resolveWorkloads(context.Background(), clientset, events)
+	finder := newProblemFinder([]clusterEvents{{Name: "prod", Owners: workloads}})
+	for _, tt := range tests {
+		obj := tt.obj.InvolvedObject
+		want := types.ObjectReference{Kind: obj.Kind, Namespace: obj.Namespace, Name: obj.Name}
+		if tt.want != nil {
+			want = *tt.want
+		}
+		if got := finder.workloadOf(obj, "prod"); got != want {
+			t.Errorf("%s %s: got workload %+v, want %+v", obj.Kind, obj.Name, got, want)
+		}
+		if got := finder.workloadOf(obj, "staging"); got.Kind != obj.Kind || got.Name != obj.Name {
+			t.Errorf("%s %s: got workload %+v from another cluster", obj.Kind, obj.Name, got)
+		}
+	}
+	if len(workloads) != 6 {
+		t.Errorf("got %d resolved workloads, want 6: %v", len(workloads), workloads)
+	}
+}
+
+func TestProblemFinder(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	event := func(pod string, first, last time.Duration, count int32, message string) corev1.Event {
+		e := problemEvent("Pod", pod)
+		e.Reason, e.Message, e.Count = "BackOff", message, count
+		e.FirstTimestamp = metav1.NewTime(start.Add(first))
+		e.LastTimestamp = metav1.NewTime(start.Add(last))
+		return e
+	}
+	web := types.ObjectReference{Kind: "Deployment", Namespace: "shop", Name: "web"}
+	finder := newProblemFinder([]clusterEvents{{Name: "prod", Owners: map[objectRef]types.ObjectReference{
+		{Kind: "Pod", Namespace: "shop", Name: "web-1"}: web,
+		{Kind: "Pod", Namespace: "shop", Name: "web-2"}: web,
+	}}})
+
+	finder.add(event("web-1", 0, 10*time.Minute, 4, "Back-off restarting failed container app"), "prod")
+	finder.add(event("web-2", 5*time.Minute, 20*time.Minute, 2, "Back-off restarting failed container sidecar"), "prod")
+	finder.add(event("db-0", time.Minute, 2*time.Minute, 1, "Back-off restarting failed container db"), "prod")
+	finder.add(event("web-1", 0, time.Minute, 9, "Back-off 10s restarting"), "prod")
+
+	want := []types.Problem{
+		{
+			Type:      "CrashLoopBackOff",
+			Cluster:   "prod",
+			Workload:  web,
+			Objects:   []string{"web-1", "web-2"},
+			Count:     6,
+			FirstSeen: start,
+			LastSeen:  start.Add(20 * time.Minute),
+			Duration:  metav1.Duration{Duration: 20 * time.Minute},
+			Message:   "Back-off restarting failed container sidecar",
+			Hint:      problemDetectors[0].Hint,
+		},
+		{
+			Type:      "CrashLoopBackOff",
+			Cluster:   "prod",
+			Workload:  types.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "db-0"},
+			Objects:   []string{"db-0"},
+			Count:     1,
+			FirstSeen: start.Add(time.Minute),
+			LastSeen:  start.Add(2 * time.Minute),
+			Duration:  metav1.Duration{Duration: time.Minute},
+			Message:   "Back-off restarting failed container db",
+			Hint:      problemDetectors[0].Hint,
+		},
+	}
+	if got := finder.list(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got problems\n%+v\nwant\n%+v", got, want)
+	}
+}
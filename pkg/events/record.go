@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/snapshot"
+)
+
+// RecordOptions contains the options for the record command
+type RecordOptions struct {
+	// Summary holds the fetch settings; the events are recorded unfiltered
+	Summary *EventSummaryOptions
+	Output  string
+
+	genericclioptions.IOStreams
+}
+
+// NewRecordOptions returns initialized RecordOptions
+func NewRecordOptions(streams genericclioptions.IOStreams) *RecordOptions {
+	summary := NewEventSummaryOptions(streams)
+	// Output settings are chosen when the snapshot is replayed
+	summary.Format = "wide"
+	summary.SortBy = "lastTimestamp"
+	return &RecordOptions{
+		Summary:   summary,
+		IOStreams: streams,
+	}
+}
+
+// Complete completes all the required options
+func (o *RecordOptions) Complete(cmd *cobra.Command, args []string) error {
+	return o.Summary.Complete(cmd, args)
+}
+
+// Validate validates the provided options
+func (o *RecordOptions) Validate() error {
+	if o.Output == "" {
+		return fmt.Errorf("--output must name the snapshot file, e.g. snapshot.json.gz")
+	}
+	return o.Summary.Validate()
+}
+
+// Run fetches the events and writes them to the snapshot file
+func (o *RecordOptions) Run() error {
+	snap, err := o.Summary.Record(context.TODO())
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Write(o.Output, snap); err != nil {
+		return err
+	}
+
+	events := 0
+	for _, cluster := range snap.Clusters {
+		events += len(cluster.Events)
+	}
+	fmt.Fprintf(o.ErrOut, "Recorded %d events from %d cluster(s) to %s\n", events, len(snap.Clusters), o.Output)
+	return nil
+}
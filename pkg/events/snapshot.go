@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/snapshot"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// Record fetches the events the way Run does and returns them as a snapshot
+func (o *EventSummaryOptions) Record(ctx context.Context) (*types.Snapshot, error) {
+	o.recording = true
+	clusters, err := o.fetchEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &types.Snapshot{
+		RecordedAt:    time.Now(),
+		MultiCluster:  o.multiCluster(),
+		AllNamespaces: o.AllNs,
+		Namespaces:    o.Namespaces,
+		ResourceArgs:  o.ResourceArgs,
+		Selector:      o.Selector,
+		Recursive:     o.Recursive,
+		Clusters:      make([]types.SnapshotCluster, 0, len(clusters)),
+	}
+	snap.Kind = types.SnapshotKind
+	snap.APIVersion = types.SummaryAPIVersion
+	for _, cluster := range clusters {
+		recorded := types.SnapshotCluster{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+			Totals:    types.Totals{Total: cluster.Total, Warnings: cluster.Warnings, Errors: cluster.Errors},
+			Forbidden: cluster.Forbidden,
+			Targets:   cluster.Targets,
+			Events:    cluster.Events,
+		}
+		for ref, workload := range cluster.Owners {
+			recorded.Owners = append(recorded.Owners, types.Owner{
+				Object:   types.ObjectReference{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name},
+				Workload: workload,
+			})
+		}
+		sort.Slice(recorded.Owners, func(i, j int) bool {
+			a, b := recorded.Owners[i].Object, recorded.Owners[j].Object
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			return a.Name < b.Name
+		})
+		if cluster.Err != nil {
+			recorded.Error = cluster.Err.Error()
+		}
+		snap.Clusters = append(snap.Clusters, recorded)
+	}
+	return snap, nil
+}
+
+// loadSnapshot reads the snapshot given with --from-snapshot
+func (o *EventSummaryOptions) loadSnapshot() error {
+	snap, err := snapshot.Read(o.FromSnapshot)
+	if err != nil {
+		return err
+	}
+	o.snapshot = snap
+	return nil
+}
+
+// loadEvents fetches the events, or takes them from the replayed snapshot,
+// and returns them with the time the summary is taken at
+func (o *EventSummaryOptions) loadEvents(ctx context.Context) ([]clusterEvents, time.Time, error) {
+	if o.snapshot == nil {
+		clusters, err := o.fetchEvents(ctx)
+		return clusters, time.Now(), err
+	}
+
+	return snapshotClusters(o.snapshot), o.snapshot.RecordedAt, nil
+}
+
+// snapshotClusters returns the recorded events in the form they were fetched in
+func snapshotClusters(snap *types.Snapshot) []clusterEvents {
+	clusters := make([]clusterEvents, 0, len(snap.Clusters))
+	for _, recorded := range snap.Clusters {
+		cluster := clusterEvents{
+			Name:      recorded.Name,
+			Namespace: recorded.Namespace,
+			Targets:   recorded.Targets,
+			Events:    recorded.Events,
+			Total:     recorded.Total,
+			Warnings:  recorded.Warnings,
+			Errors:    recorded.Errors,
+			Forbidden: recorded.Forbidden,
+		}
+		if len(recorded.Owners) > 0 {
+			cluster.Owners = make(map[objectRef]types.ObjectReference, len(recorded.Owners))
+			for _, owner := range recorded.Owners {
+				obj := owner.Object
+				cluster.Owners[objectRef{Kind: obj.Kind, Namespace: obj.Namespace, Name: obj.Name}] = owner.Workload
+			}
+		}
+		if recorded.Error != "" {
+			cluster.Err = errors.New(recorded.Error)
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// buildSummary assembles the summary document rendered by the output formats
+func (o *EventSummaryOptions) buildSummary(groups map[string]*types.GroupSummary, keys []string, clusters []clusterEvents, since, until time.Time) *types.Summary {
+	summary := &types.Summary{
+		Since:    since,
+		Until:    until,
+		Clusters: o.clusterTotals(clusters),
+		Groups:   []types.Group{},
+	}
+	summary.Kind = types.SummaryKind
+	summary.APIVersion = types.SummaryAPIVersion
+	if o.GroupBy != "" {
+		summary.GroupBy = strings.Split(o.GroupBy, ",")
+	}
+
+	for _, cluster := range clusters {
+		summary.Totals.Total += cluster.Total
+		summary.Totals.Warnings += cluster.Warnings
+		summary.Totals.Errors += cluster.Errors
+	}
+
+	for _, key := range keys {
+		group := groups[key]
+		summary.Filtered.Total += group.Total
+		summary.Filtered.Warnings += group.Warnings
+		summary.Filtered.Errors += group.Errors
+		summary.Groups = append(summary.Groups, types.Group{Key: key, GroupSummary: group})
+	}
+	// Add initial totals to all groups
+	for _, group := range summary.Groups {
+		group.InitialTotal = summary.Totals.Total
+		group.InitialWarnings = summary.Totals.Warnings
+		group.InitialErrors = summary.Totals.Errors
+	}
+
+	return summary
+}
+
+// clusterTotals returns the per-cluster totals in multi-cluster mode
+func (o *EventSummaryOptions) clusterTotals(clusters []clusterEvents) []types.ClusterTotals {
+	if !o.multiCluster() {
+		return nil
+	}
+
+	var totals []types.ClusterTotals
+	for _, cluster := range clusters {
+		ct := types.ClusterTotals{Name: cluster.Name}
+		if cluster.Err != nil {
+			ct.Error = cluster.Err.Error()
+		} else {
+			ct.Totals = types.Totals{Total: cluster.Total, Warnings: cluster.Warnings, Errors: cluster.Errors}
+		}
+		totals = append(totals, ct)
+	}
+	return totals
+}
@@ -0,0 +1,231 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// objectRef identifies an involved object by kind, namespace and name
+type objectRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// targetSet holds the objects whose events should be summarized
+type targetSet struct {
+	uids map[k8stypes.UID]bool
+	refs map[objectRef]bool
+	// objects lists the targets in the order they were resolved
+	objects []types.ObjectReference
+
+	// namespaces holds the namespaces of namespaced targets
+	namespaces map[string]bool
+	// clusterScoped is set when any target is cluster-scoped (e.g. a Node)
+	clusterScoped bool
+}
+
+func newTargetSet() *targetSet {
+	return &targetSet{
+		uids:       make(map[k8stypes.UID]bool),
+		refs:       make(map[objectRef]bool),
+		namespaces: make(map[string]bool),
+	}
+}
+
+func (t *targetSet) add(uid k8stypes.UID, ref objectRef) {
+	if uid != "" {
+		t.uids[uid] = true
+	}
+	if !t.refs[ref] {
+		t.objects = append(t.objects, types.ObjectReference{
+			Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name, UID: string(uid),
+		})
+	}
+	t.refs[ref] = true
+	if ref.Namespace != "" {
+		t.namespaces[ref.Namespace] = true
+	}
+}
+
+// matches reports whether the event's involved object is one of the targets.
+// Events are matched by UID, or by kind/namespace/name so that events of a
+// previous incarnation of the same object (e.g. a recreated pod) are kept.
+func (t *targetSet) matches(event corev1.Event) bool {
+	obj := event.InvolvedObject
+	if obj.UID != "" && t.uids[obj.UID] {
+		return true
+	}
+	return t.refs[objectRef{Kind: obj.Kind, Namespace: obj.Namespace, Name: obj.Name}]
+}
+
+// resolveTargets resolves the resource arguments and label selector into the
+// set of objects whose events should be summarized
+func (o *EventSummaryOptions) resolveTargets(ctx context.Context, flags *genericclioptions.ConfigFlags, clientset kubernetes.Interface, namespace string) (*targetSet, error) {
+	args := o.ResourceArgs
+	if len(args) == 0 {
+		// A bare label selector matches against the "all" category, like kubectl get -l
+		args = []string{"all"}
+	}
+
+	infos, err := resource.NewBuilder(flags).
+		Unstructured().
+		NamespaceParam(namespace).DefaultNamespace().AllNamespaces(o.AllNs).
+		LabelSelectorParam(o.Selector).
+		ResourceTypeOrNameArgs(true, args...).
+		ContinueOnError().
+		Latest().
+		Flatten().
+		Do().
+		Infos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve resources: %v", err)
+	}
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("no resources found matching the specified arguments")
+	}
+
+	targets := newTargetSet()
+	for _, info := range infos {
+		accessor, err := meta.Accessor(info.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", info.ObjectName(), err)
+		}
+		targets.add(accessor.GetUID(), objectRef{
+			Kind:      info.Mapping.GroupVersionKind.Kind,
+			Namespace: accessor.GetNamespace(),
+			Name:      accessor.GetName(),
+		})
+		if !info.Namespaced() {
+			targets.clusterScoped = true
+		}
+	}
+
+	if o.Recursive {
+		if err := addOwnedObjects(ctx, clientset, targets); err != nil {
+			return nil, err
+		}
+	}
+
+	return targets, nil
+}
+
+// ownedObject is an object that may be owned by one of the targets
+type ownedObject struct {
+	uid    k8stypes.UID
+	ref    objectRef
+	owners []metav1.OwnerReference
+	claims []string
+}
+
+// addOwnedObjects adds the ReplicaSets, Jobs, Pods and PersistentVolumeClaims
+// transitively owned by the targets. PVCs created from StatefulSet volume
+// claim templates carry no owner reference, so PVCs mounted by targeted or
+// owned pods are included as well.
+func addOwnedObjects(ctx context.Context, clientset kubernetes.Interface, targets *targetSet) error {
+	for namespace := range targets.namespaces {
+		objects, err := listOwnableObjects(ctx, clientset, namespace)
+		if err != nil {
+			return err
+		}
+
+		byUID := make(map[k8stypes.UID]ownedObject)
+		children := make(map[k8stypes.UID][]ownedObject)
+		pvcs := make(map[string]ownedObject)
+		for _, obj := range objects {
+			byUID[obj.uid] = obj
+			for _, owner := range obj.owners {
+				children[owner.UID] = append(children[owner.UID], obj)
+			}
+			if obj.ref.Kind == "PersistentVolumeClaim" {
+				pvcs[obj.ref.Name] = obj
+			}
+		}
+
+		var queue []k8stypes.UID
+		for uid := range targets.uids {
+			queue = append(queue, uid)
+		}
+		for len(queue) > 0 {
+			uid := queue[0]
+			queue = queue[1:]
+			for _, claim := range byUID[uid].claims {
+				if pvc, ok := pvcs[claim]; ok && !targets.uids[pvc.uid] {
+					targets.add(pvc.uid, pvc.ref)
+				}
+			}
+			for _, child := range children[uid] {
+				if targets.uids[child.uid] {
+					continue
+				}
+				targets.add(child.uid, child.ref)
+				queue = append(queue, child.uid)
+			}
+		}
+	}
+	return nil
+}
+
+// listOwnableObjects lists the objects in a namespace that are commonly owned
+// by workload controllers
+func listOwnableObjects(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]ownedObject, error) {
+	var objects []ownedObject
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %v", err)
+	}
+	for _, rs := range replicaSets.Items {
+		objects = append(objects, newOwnedObject("ReplicaSet", rs.ObjectMeta))
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %v", err)
+	}
+	for _, job := range jobs.Items {
+		objects = append(objects, newOwnedObject("Job", job.ObjectMeta))
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	for _, pod := range pods.Items {
+		obj := newOwnedObject("Pod", pod.ObjectMeta)
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				obj.claims = append(obj.claims, volume.PersistentVolumeClaim.ClaimName)
+			}
+		}
+		objects = append(objects, obj)
+	}
+
+	claims, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistentvolumeclaims: %v", err)
+	}
+	for _, pvc := range claims.Items {
+		objects = append(objects, newOwnedObject("PersistentVolumeClaim", pvc.ObjectMeta))
+	}
+
+	return objects, nil
+}
+
+func newOwnedObject(kind string, objMeta metav1.ObjectMeta) ownedObject {
+	return ownedObject{
+		uid:    objMeta.UID,
+		ref:    objectRef{Kind: kind, Namespace: objMeta.Namespace, Name: objMeta.Name},
+		owners: objMeta.OwnerReferences,
+	}
+}
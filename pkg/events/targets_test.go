@@ -0,0 +1,389 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+// apiResource describes a resource served by the fake API server
+type apiResource struct {
+	groupVersion string
+	name         string
+	kind         string
+	namespaced   bool
+}
+
+var apiResources = []apiResource{
+	{"v1", "pods", "Pod", true},
+	{"v1", "services", "Service", true},
+	{"v1", "persistentvolumeclaims", "PersistentVolumeClaim", true},
+	{"v1", "nodes", "Node", false},
+	{"apps/v1", "deployments", "Deployment", true},
+	{"apps/v1", "replicasets", "ReplicaSet", true},
+	{"apps/v1", "statefulsets", "StatefulSet", true},
+	{"batch/v1", "jobs", "Job", true},
+	{"batch/v1", "cronjobs", "CronJob", true},
+}
+
+// objectMeta returns the metadata of an object in the shop namespace,
+// controlled by owner if set
+func objectMeta(name string, labels map[string]string, owner metav1.Object, ownerKind string) metav1.ObjectMeta {
+	objMeta := metav1.ObjectMeta{Name: name, Namespace: "shop", UID: k8stypes.UID("uid-" + name), Labels: labels}
+	if owner != nil {
+		objMeta.OwnerReferences = []metav1.OwnerReference{{
+			Kind: ownerKind, Name: owner.GetName(), UID: owner.GetUID(), Controller: ptr.To(true),
+		}}
+	}
+	return objMeta
+}
+
+// testObjects returns a Deployment, a StatefulSet and a CronJob with the
+// objects they own, an unrelated Pod and PVC, and a Node
+func testObjects() []runtime.Object {
+	web := &appsv1.Deployment{ObjectMeta: objectMeta("web", map[string]string{"app": "web"}, nil, "")}
+	webRS := &appsv1.ReplicaSet{ObjectMeta: objectMeta("web-7d4b9c", map[string]string{"app": "web"}, web, "Deployment")}
+	webPod := &corev1.Pod{ObjectMeta: objectMeta("web-7d4b9c-x2x4z", map[string]string{"app": "web"}, webRS, "ReplicaSet")}
+
+	db := &appsv1.StatefulSet{ObjectMeta: objectMeta("db", map[string]string{"app": "db"}, nil, "")}
+	dbPod := &corev1.Pod{
+		ObjectMeta: objectMeta("db-0", map[string]string{"app": "db"}, db, "StatefulSet"),
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "data",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"}},
+		}}},
+	}
+	// Created from the volume claim template, so not owned by the StatefulSet
+	dbClaim := &corev1.PersistentVolumeClaim{ObjectMeta: objectMeta("data-db-0", map[string]string{"app": "db"}, nil, "")}
+
+	backup := &batchv1.CronJob{ObjectMeta: objectMeta("backup", nil, nil, "")}
+	backupJob := &batchv1.Job{ObjectMeta: objectMeta("backup-28512345", nil, backup, "CronJob")}
+	backupPod := &corev1.Pod{ObjectMeta: objectMeta("backup-28512345-k7h2p", nil, backupJob, "Job")}
+
+	return []runtime.Object{
+		web, webRS, webPod,
+		db, dbPod, dbClaim,
+		backup, backupJob, backupPod,
+		&corev1.Pod{ObjectMeta: objectMeta("api-0", map[string]string{"app": "api"}, nil, "")},
+		&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta("scratch", nil, nil, "")},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "uid-node-1", Labels: map[string]string{"role": "worker"}}},
+	}
+}
+
+// fakeAPIServer serves discovery and read-only list and get requests for
+// the objects, enough for the resource builder
+func fakeAPIServer(t *testing.T, objects []runtime.Object) *httptest.Server {
+	stored := make(map[string][]map[string]interface{})
+	for _, obj := range objects {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kind := reflect.TypeOf(obj).Elem().Name()
+		for _, r := range apiResources {
+			if r.kind == kind {
+				u["apiVersion"], u["kind"] = r.groupVersion, r.kind
+				stored[r.name] = append(stored[r.name], u)
+			}
+		}
+	}
+
+	write := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		switch path {
+		case "api":
+			write(w, metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}})
+			return
+		case "apis":
+			list := metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}}
+			for _, group := range []string{"apps", "batch"} {
+				version := metav1.GroupVersionForDiscovery{GroupVersion: group + "/v1", Version: "v1"}
+				list.Groups = append(list.Groups, metav1.APIGroup{
+					Name: group, Versions: []metav1.GroupVersionForDiscovery{version}, PreferredVersion: version,
+				})
+			}
+			write(w, list)
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(path, "api/"), "apis/"), "/")
+		groupVersion := parts[0]
+		if strings.HasPrefix(path, "apis/") {
+			groupVersion, parts = parts[0]+"/"+parts[1], parts[1:]
+		}
+		parts = parts[1:]
+		if len(parts) == 0 {
+			list := metav1.APIResourceList{TypeMeta: metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"}, GroupVersion: groupVersion}
+			for _, r := range apiResources {
+				if r.groupVersion == groupVersion {
+					resource := metav1.APIResource{Name: r.name, Kind: r.kind, Namespaced: r.namespaced, Verbs: []string{"get", "list"}}
+					if r.kind != "PersistentVolumeClaim" && r.kind != "Node" {
+						resource.Categories = []string{"all"}
+					}
+					list.APIResources = append(list.APIResources, resource)
+				}
+			}
+			write(w, list)
+			return
+		}
+
+		var namespace string
+		if parts[0] == "namespaces" && len(parts) > 2 {
+			namespace, parts = parts[1], parts[2:]
+		}
+		selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+		if err != nil {
+			t.Errorf("invalid label selector: %v", err)
+		}
+		var items []map[string]interface{}
+		for _, u := range stored[parts[0]] {
+			objMeta := u["metadata"].(map[string]interface{})
+			objLabels := make(labels.Set)
+			if l, ok := objMeta["labels"].(map[string]interface{}); ok {
+				for k, v := range l {
+					objLabels[k] = v.(string)
+				}
+			}
+			ns, _ := objMeta["namespace"].(string)
+			if (namespace == "" || ns == namespace) && selector.Matches(objLabels) &&
+				(len(parts) == 1 || objMeta["name"] == parts[1]) {
+				items = append(items, u)
+			}
+		}
+
+		if len(parts) > 1 {
+			if len(items) == 0 {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404,"message":"%s %q not found"}`, parts[0], parts[1])
+				return
+			}
+			write(w, items[0])
+			return
+		}
+		var kind string
+		for _, r := range apiResources {
+			if r.name == parts[0] {
+				kind = r.kind
+			}
+		}
+		write(w, map[string]interface{}{
+			"apiVersion": groupVersion,
+			"kind":       kind + "List",
+			"metadata":   map[string]interface{}{},
+			"items":      append([]map[string]interface{}{}, items...),
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testConfigFlags returns client flags for a kubeconfig pointing at server
+func testConfigFlags(t *testing.T, server *httptest.Server) *genericclioptions.ConfigFlags {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	content := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: fake
+  cluster:
+    server: %s
+contexts:
+- name: fake
+  context:
+    cluster: fake
+    user: fake
+users:
+- name: fake
+  user: {}
+current-context: fake
+`, server.URL)
+	if err := os.WriteFile(kubeconfig, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	flags := genericclioptions.NewConfigFlags(true)
+	flags.KubeConfig = ptr.To(kubeconfig)
+	flags.CacheDir = ptr.To(filepath.Join(dir, "cache"))
+	return flags
+}
+
+func targetNames(targets *targetSet) []string {
+	var names []string
+	for ref := range targets.refs {
+		names = append(names, ref.Kind+" "+ref.Namespace+"/"+ref.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestResolveTargets(t *testing.T) {
+	objects := testObjects()
+	flags := testConfigFlags(t, fakeAPIServer(t, objects))
+	clientset := fake.NewSimpleClientset(objects...)
+
+	tests := []struct {
+		name          string
+		args          []string
+		selector      string
+		recursive     bool
+		want          []string
+		clusterScoped bool
+		wantErr       string
+	}{
+		{
+			name:     "selector only",
+			selector: "app=web",
+			want:     []string{"Deployment shop/web", "Pod shop/web-7d4b9c-x2x4z", "ReplicaSet shop/web-7d4b9c"},
+		},
+		{
+			name:     "selector only, outside the all category",
+			selector: "app=db",
+			// The PVC matches the selector but, like kubectl get all, is
+			// not listed
+			want: []string{"Pod shop/db-0", "StatefulSet shop/db"},
+		},
+		{
+			name: "resource argument",
+			args: []string{"deployment/web"},
+			want: []string{"Deployment shop/web"},
+		},
+		{
+			name:      "recursive deployment",
+			args:      []string{"deployment/web"},
+			recursive: true,
+			want:      []string{"Deployment shop/web", "Pod shop/web-7d4b9c-x2x4z", "ReplicaSet shop/web-7d4b9c"},
+		},
+		{
+			name:      "recursive statefulset with claim",
+			args:      []string{"statefulset/db"},
+			recursive: true,
+			want:      []string{"PersistentVolumeClaim shop/data-db-0", "Pod shop/db-0", "StatefulSet shop/db"},
+		},
+		{
+			name:      "recursive cronjob",
+			args:      []string{"cronjob/backup"},
+			recursive: true,
+			want:      []string{"CronJob shop/backup", "Job shop/backup-28512345", "Pod shop/backup-28512345-k7h2p"},
+		},
+		{
+			name:      "recursive pod with claim",
+			args:      []string{"pod/db-0"},
+			recursive: true,
+			want:      []string{"PersistentVolumeClaim shop/data-db-0", "Pod shop/db-0"},
+		},
+		{
+			name:      "recursive selector",
+			selector:  "app=db",
+			recursive: true,
+			want:      []string{"PersistentVolumeClaim shop/data-db-0", "Pod shop/db-0", "StatefulSet shop/db"},
+		},
+		{
+			name:          "cluster-scoped",
+			args:          []string{"node/node-1"},
+			want:          []string{"Node /node-1"},
+			clusterScoped: true,
+		},
+		{
+			name:          "cluster-scoped and namespaced",
+			args:          []string{"node/node-1", "pod/api-0"},
+			want:          []string{"Node /node-1", "Pod shop/api-0"},
+			clusterScoped: true,
+		},
+		{
+			name:     "nothing matches",
+			selector: "app=none",
+			wantErr:  "no resources found",
+		},
+		{
+			name:    "missing object",
+			args:    []string{"deployment/none"},
+			wantErr: "failed to resolve resources",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.ResourceArgs, o.Selector, o.Recursive = tt.args, tt.selector, tt.recursive
+
+			targets, err := o.resolveTargets(context.Background(), flags, clientset, "shop")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := targetNames(targets); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got targets %v, want %v", got, tt.want)
+			}
+			if targets.clusterScoped != tt.clusterScoped {
+				t.Errorf("got clusterScoped=%v, want %v", targets.clusterScoped, tt.clusterScoped)
+			}
+			for ref := range targets.refs {
+				if !targets.uids[k8stypes.UID("uid-"+ref.Name)] {
+					t.Errorf("%s %s: UID not targeted", ref.Kind, ref.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestTargetSetMatches(t *testing.T) {
+	targets := newTargetSet()
+	targets.add("uid-web-0", objectRef{Kind: "Pod", Namespace: "shop", Name: "web-0"})
+	targets.add("uid-node-1", objectRef{Kind: "Node", Name: "node-1"})
+
+	tests := []struct {
+		obj  corev1.ObjectReference
+		want bool
+	}{
+		{corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-0", UID: "uid-web-0"}, true},
+		// A previous incarnation of the pod
+		{corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-0", UID: "uid-old"}, true},
+		// Matched by UID alone
+		{corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "renamed", UID: "uid-web-0"}, true},
+		{corev1.ObjectReference{Kind: "Node", Name: "node-1"}, true},
+		{corev1.ObjectReference{Kind: "Pod", Namespace: "other", Name: "web-0"}, false},
+		{corev1.ObjectReference{Kind: "Service", Namespace: "shop", Name: "web-0"}, false},
+	}
+	for _, tt := range tests {
+		if got := targets.matches(corev1.Event{InvolvedObject: tt.obj}); got != tt.want {
+			t.Errorf("%+v: got %v, want %v", tt.obj, got, tt.want)
+		}
+	}
+
+	if !reflect.DeepEqual(targets.namespaces, map[string]bool{"shop": true}) {
+		t.Errorf("got namespaces %v, want only shop", targets.namespaces)
+	}
+}
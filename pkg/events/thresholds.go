@@ -0,0 +1,181 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+const (
+	// ExitRuntimeError is the exit code when the command fails to run
+	ExitRuntimeError = 1
+	// ExitThresholdBreached is the exit code when a --fail-on threshold is breached
+	ExitThresholdBreached = 2
+)
+
+// ThresholdError is returned by Run when a --fail-on threshold is breached
+type ThresholdError struct {
+	Breached []string
+}
+
+func (e *ThresholdError) Error() string {
+	return "threshold breached: " + strings.Join(e.Breached, ", ")
+}
+
+// ExitCode returns the exit code of a command that returned err
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var thresholdErr *ThresholdError
+	if errors.As(err, &thresholdErr) {
+		return ExitThresholdBreached
+	}
+	return ExitRuntimeError
+}
+
+// threshold is a parsed --fail-on rule such as "warnings>0", "errors>=3" or
+// "reason=FailedScheduling"
+type threshold struct {
+	rule string
+	// field is one of total, warnings, errors or reason
+	field  string
+	reason string
+	op     string
+	value  int
+}
+
+var thresholdOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// parseThreshold parses a --fail-on or --alert-on rule, flag naming the
+// flag in errors. A reason rule without a comparison breaches as soon as one
+// event with that reason is found.
+func parseThreshold(flag, rule string) (threshold, error) {
+	t := threshold{rule: rule}
+	expr := strings.TrimSpace(rule)
+
+	if strings.HasPrefix(expr, "reason=") {
+		t.field = "reason"
+		t.reason = strings.TrimPrefix(expr, "reason=")
+		t.op, t.value = ">", 0
+		for _, op := range thresholdOps {
+			if i := strings.Index(t.reason, op); i >= 0 {
+				value, err := strconv.Atoi(strings.TrimSpace(t.reason[i+len(op):]))
+				if err != nil {
+					return t, fmt.Errorf("invalid %s rule %q: %v", flag, rule, err)
+				}
+				t.reason, t.op, t.value = strings.TrimSpace(t.reason[:i]), op, value
+				break
+			}
+		}
+		if t.reason == "" {
+			return t, fmt.Errorf("invalid %s rule %q: missing reason", flag, rule)
+		}
+		return t, nil
+	}
+
+	for _, op := range thresholdOps {
+		i := strings.Index(expr, op)
+		if i < 0 {
+			continue
+		}
+		t.field = strings.TrimSpace(expr[:i])
+		t.op = op
+		value, err := strconv.Atoi(strings.TrimSpace(expr[i+len(op):]))
+		if err != nil {
+			return t, fmt.Errorf("invalid %s rule %q: %v", flag, rule, err)
+		}
+		t.value = value
+		switch t.field {
+		case "total", "warnings", "errors":
+			return t, nil
+		default:
+			return t, fmt.Errorf("invalid %s rule %q: field must be one of: total, warnings, errors, reason", flag, rule)
+		}
+	}
+	return t, fmt.Errorf("invalid %s rule %q: expected e.g. warnings>0, errors>=3 or reason=FailedScheduling", flag, rule)
+}
+
+// actual returns the value of the rule's field over the summary's groups
+func (t threshold) actual(summary *types.Summary) int {
+	actual := 0
+	for _, group := range summary.Groups {
+		actual += t.groupActual(group)
+	}
+	return actual
+}
+
+// groupActual returns the value of the rule's field in a single group
+func (t threshold) groupActual(group types.Group) int {
+	switch t.field {
+	case "total":
+		return group.Total
+	case "warnings":
+		return group.Warnings
+	case "errors":
+		return group.Errors
+	case "reason":
+		return group.Reasons[t.reason]
+	}
+	return 0
+}
+
+// breached reports whether the actual value satisfies the rule's comparison
+func (t threshold) breached(actual int) bool {
+	switch t.op {
+	case ">=":
+		return actual >= t.value
+	case "<=":
+		return actual <= t.value
+	case "==":
+		return actual == t.value
+	case "!=":
+		return actual != t.value
+	case ">":
+		return actual > t.value
+	case "<":
+		return actual < t.value
+	}
+	return false
+}
+
+// evaluateThresholds evaluates the --fail-on rules against the group totals
+func (o *EventSummaryOptions) evaluateThresholds(summary *types.Summary) []types.ThresholdResult {
+	var results []types.ThresholdResult
+	for _, t := range o.thresholds {
+		results = append(results, t.result(t.actual(summary)))
+	}
+	return results
+}
+
+// result returns the outcome of the rule for the actual value
+func (t threshold) result(actual int) types.ThresholdResult {
+	field := t.field
+	if t.field == "reason" {
+		field = "count"
+	}
+	return types.ThresholdResult{
+		Rule:     t.rule,
+		Field:    field,
+		Actual:   actual,
+		Breached: t.breached(actual),
+	}
+}
+
+// checkThresholds returns a ThresholdError if any --fail-on rule of the
+// summary is breached
+func (o *EventSummaryOptions) checkThresholds(summary *types.Summary) error {
+	var breached []string
+	for _, result := range summary.Thresholds {
+		if result.Breached {
+			breached = append(breached, result.String())
+		}
+	}
+	if len(breached) > 0 {
+		return &ThresholdError{Breached: breached}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    threshold
+		wantErr string
+	}{
+		{rule: "warnings>0", want: threshold{field: "warnings", op: ">", value: 0}},
+		{rule: "errors>=3", want: threshold{field: "errors", op: ">=", value: 3}},
+		{rule: "total<=100", want: threshold{field: "total", op: "<=", value: 100}},
+		{rule: "warnings==2", want: threshold{field: "warnings", op: "==", value: 2}},
+		{rule: "errors!=0", want: threshold{field: "errors", op: "!=", value: 0}},
+		{rule: "total<5", want: threshold{field: "total", op: "<", value: 5}},
+		{rule: " warnings > 10 ", want: threshold{field: "warnings", op: ">", value: 10}},
+		{rule: "reason=FailedScheduling", want: threshold{field: "reason", reason: "FailedScheduling", op: ">", value: 0}},
+		{rule: "reason=BackOff>=5", want: threshold{field: "reason", reason: "BackOff", op: ">=", value: 5}},
+		{rule: "reason=BackOff<=5", want: threshold{field: "reason", reason: "BackOff", op: "<=", value: 5}},
+		{rule: "reason=Unhealthy==1", want: threshold{field: "reason", reason: "Unhealthy", op: "==", value: 1}},
+		{rule: "reason=Unhealthy!=0", want: threshold{field: "reason", reason: "Unhealthy", op: "!=", value: 0}},
+		{rule: "reason=Evicted > 2", want: threshold{field: "reason", reason: "Evicted", op: ">", value: 2}},
+		{rule: "reason=Evicted<2", want: threshold{field: "reason", reason: "Evicted", op: "<", value: 2}},
+
+		{rule: "", wantErr: "expected e.g. warnings>0"},
+		{rule: "warnings", wantErr: "expected e.g. warnings>0"},
+		{rule: "warnings>", wantErr: "invalid syntax"},
+		{rule: "warnings>many", wantErr: "invalid syntax"},
+		{rule: "warnings=>3", wantErr: "field must be one of"},
+		{rule: "latency>3", wantErr: "field must be one of"},
+		{rule: ">3", wantErr: "field must be one of"},
+		{rule: "reason=", wantErr: "missing reason"},
+		{rule: "reason=>3", wantErr: "missing reason"},
+		{rule: "reason=BackOff>lots", wantErr: "invalid syntax"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := parseThreshold("fail-on", tt.rule)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				if !strings.HasPrefix(err.Error(), fmt.Sprintf("invalid fail-on rule %q", tt.rule)) {
+					t.Errorf("error %q does not name the flag and rule", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.want.rule = tt.rule
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholdBreached(t *testing.T) {
+	tests := []struct {
+		rule   string
+		actual int
+		want   bool
+	}{
+		{"warnings>0", 0, false},
+		{"warnings>0", 1, true},
+		{"errors>=3", 2, false},
+		{"errors>=3", 3, true},
+		{"total<=100", 100, true},
+		{"total<=100", 101, false},
+		{"warnings==2", 2, true},
+		{"warnings==2", 3, false},
+		{"errors!=0", 0, false},
+		{"errors!=0", 1, true},
+		{"total<5", 4, true},
+		{"total<5", 5, false},
+	}
+	for _, tt := range tests {
+		rule, err := parseThreshold("fail-on", tt.rule)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.rule, err)
+		}
+		if got := rule.breached(tt.actual); got != tt.want {
+			t.Errorf("%s with %d: got breached=%v, want %v", tt.rule, tt.actual, got, tt.want)
+		}
+	}
+}
+
+// thresholdEvent returns a warning event last seen at last, repeated count times
+func thresholdEvent(reason string, last time.Time, count int32) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: reason, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-0"},
+		Reason:         reason,
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(last),
+		Count:          count,
+	}
+}
+
+func TestCheckThresholdsExitCode(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	events := []corev1.Event{
+		thresholdEvent("BackOff", now.Add(-time.Minute), 4),
+		thresholdEvent("Unhealthy", now.Add(-5*time.Minute), 1),
+	}
+
+	tests := []struct {
+		rules    []string
+		want     int
+		breached string
+	}{
+		{rules: nil, want: 0},
+		{rules: []string{"warnings>2", "reason=FailedScheduling"}, want: 0},
+		{rules: []string{"warnings>=2"}, want: ExitThresholdBreached, breached: "warnings>=2 (warnings=2)"},
+		{rules: []string{"errors>5", "reason=Unhealthy"}, want: ExitThresholdBreached, breached: "reason=Unhealthy (count=1)"},
+	}
+	for _, tt := range tests {
+		o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+		o.Since = time.Hour
+		for _, rule := range tt.rules {
+			threshold, err := parseThreshold("fail-on", rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.thresholds = append(o.thresholds, threshold)
+		}
+
+		summary, _, err := o.summarize([]clusterEvents{{Events: events}}, now, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = o.checkThresholds(summary)
+		if got := ExitCode(err); got != tt.want {
+			t.Errorf("%v: got exit code %d, want %d (%v)", tt.rules, got, tt.want, err)
+		}
+		if tt.breached != "" && (err == nil || err.Error() != "threshold breached: "+tt.breached) {
+			t.Errorf("%v: got error %v, want %s breached", tt.rules, err, tt.breached)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("failed to list events: connection refused"), ExitRuntimeError},
+		{&ThresholdError{Breached: []string{"warnings>0 (warnings=1)"}}, ExitThresholdBreached},
+		{fmt.Errorf("watch: %w", &ThresholdError{}), ExitThresholdBreached},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+	if ExitRuntimeError != 1 || ExitThresholdBreached != 2 {
+		t.Errorf("got exit codes %d and %d, want 1 and 2", ExitRuntimeError, ExitThresholdBreached)
+	}
+}
+
+func TestErrorsIndependentOfGroupBy(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	events := []corev1.Event{
+		thresholdEvent("BackOff", now.Add(-time.Minute), 4),
+		thresholdEvent("FailedMount", now.Add(-2*time.Minute), 1),
+		thresholdEvent("Unhealthy", now.Add(-5*time.Minute), 1),
+	}
+
+	for _, groupBy := range []string{"", "reason", "namespace,reason"} {
+		o := NewEventSummaryOptions(genericclioptions.NewTestIOStreamsDiscard())
+		o.Since = time.Hour
+		o.GroupBy = groupBy
+		rule, err := parseThreshold("fail-on", "errors>=2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.thresholds = append(o.thresholds, rule)
+
+		summary, _, err := o.summarize([]clusterEvents{{Events: events}}, now, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		groupErrors := 0
+		for _, group := range summary.Groups {
+			groupErrors += group.Errors
+		}
+		if summary.Filtered.Errors != 2 || groupErrors != 2 {
+			t.Errorf("group by %q: got %d filtered and %d group errors, want 2", groupBy, summary.Filtered.Errors, groupErrors)
+		}
+		if got := ExitCode(o.checkThresholds(summary)); got != ExitThresholdBreached {
+			t.Errorf("group by %q: got exit code %d, want %d", groupBy, got, ExitThresholdBreached)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	
+	"github.com/nareshku/kubectl-event-summary/pkg/notify"
+	"github.com/nareshku/kubectl-event-summary/pkg/store"
 	"github.com/nareshku/kubectl-event-summary/pkg/types"
 )
 
@@ -20,6 +22,84 @@ type EventSummaryOptions struct {
 	Filter      string
 	Severity    types.Severity
 	Search      string
+	NoHeaders   bool
+
+	// ResourceArgs and Selector restrict the summary to events of specific objects
+	ResourceArgs []string
+	Selector     string
+	Recursive    bool
+
+	// Contexts and AllContexts fetch events from several clusters in parallel
+	Contexts       []string
+	AllContexts    bool
+	ClusterTimeout time.Duration
+
+	// Namespaces lists events namespace by namespace, with at most
+	// MaxConcurrency requests in flight
+	Namespaces     []string
+	MaxConcurrency int
+
+	// FailOn holds threshold rules that make the command exit with
+	// ExitThresholdBreached, e.g. "warnings>0" or "reason=FailedScheduling"
+	FailOn     []string
+	thresholds []threshold
+
+	// Watch repeats the summary every WatchInterval until interrupted;
+	// streamed records the events already streamed by the previous summary
+	Watch         bool
+	WatchInterval time.Duration
+	streamed      map[string]bool
+
+	// Notify posts the summary to a webhook after it is printed
+	Notify notify.Options
+
+	// AlertmanagerURL receives an alert for each group breaching an AlertOn
+	// rule, labeled with the group key and AlertLabels; firing tracks the
+	// alerts of the previous summary in watch mode
+	AlertmanagerURL string
+	AlertOn         []string
+	AlertLabels     []string
+	alertRules      []threshold
+	firing          map[string]notify.Alert
+
+	// OTLP, Loki, Elasticsearch and CloudEvents receive the filtered
+	// events; exported records the events exported by the previous summary
+	// in watch mode
+	OTLP          notify.OTLPOptions
+	Loki          notify.Endpoint
+	Elasticsearch notify.Endpoint
+	CloudEvents   notify.Endpoint
+	ESIndex       string
+	exported      map[string]bool
+
+	// FromSnapshot summarizes the events of a recorded snapshot instead of
+	// fetching them
+	FromSnapshot string
+	snapshot     *types.Snapshot
+
+	// Anomalies ranks the groups whose count deviates from their counts in
+	// the BaselineWindows windows of the same length preceding the summary
+	// window, or ending when BaselineSnapshot was recorded, by at least
+	// AnomalyThreshold standard deviations
+	Anomalies        bool
+	BaselineWindows  int
+	BaselineSnapshot string
+	AnomalyThreshold float64
+	baseline         *types.Snapshot
+
+	// Problems reports the known failure patterns found in the events,
+	// such as crash loops and image pull failures, per workload; recording
+	// resolves the workloads for the snapshot even without Problems
+	Problems  bool
+	recording bool
+
+	// Store keeps the events seen in watch mode and by serve; summaries
+	// whose window reaches back further than EventTTL, how long the API
+	// server keeps events, also read the events it still holds
+	Store        store.Options
+	EventTTL     time.Duration
+	store        *store.Store
+	storeCluster string
 
 	genericclioptions.IOStreams
 }
@@ -30,5 +110,7 @@ func NewEventSummaryOptions(streams genericclioptions.IOStreams) *EventSummaryOp
 		ConfigFlags: genericclioptions.NewConfigFlags(true),
 		IOStreams:   streams,
 		Severity:    types.SeverityAll,
+		Notify:      notify.Options{Client: notify.Client{Backoff: time.Second}},
+		EventTTL:    time.Hour,
 	}
 } 
\ No newline at end of file
@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Alert is an alert of the Alertmanager v2 API
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       *time.Time        `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// PostAlerts posts alerts to the Alertmanager at baseURL
+func (c *Client) PostAlerts(ctx context.Context, baseURL string, alerts []Alert) error {
+	payload, err := json.Marshal(alerts)
+	if err != nil {
+		return fmt.Errorf("failed to encode alerts: %v", err)
+	}
+	if err := c.Post(ctx, strings.TrimSuffix(baseURL, "/")+"/api/v2/alerts", payload); err != nil {
+		return fmt.Errorf("failed to send alerts: %v", err)
+	}
+	return nil
+}
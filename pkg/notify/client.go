@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Post posts the JSON payload to url. Network errors, 429 and 5xx responses
+// are retried with exponential backoff; a 429 response's Retry-After header
+// takes precedence over the backoff.
+func (c *Client) Post(ctx context.Context, url string, payload []byte) error {
+	_, err := c.PostWithHeader(ctx, url, http.Header{"Content-Type": {"application/json"}}, payload)
+	return err
+}
+
+// PostWithHeader posts the payload to url with the given request header,
+// retrying like Post, and returns the body of the successful response
+func (c *Client) PostWithHeader(ctx context.Context, url string, header http.Header, payload []byte) ([]byte, error) {
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		body, wait, err := c.post(ctx, url, header, payload)
+		if err == nil {
+			return body, nil
+		}
+		if wait < 0 || attempt >= c.Retries {
+			return nil, err
+		}
+		if wait == 0 {
+			wait = backoff
+			backoff *= 2
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// maxResponseSize bounds the response body read from successful requests
+const maxResponseSize = 16 << 20
+
+// post sends the payload once and returns the response body. On failure it
+// returns how long to wait before retrying: 0 for the default backoff, or -1
+// if the error is not worth retrying.
+func (c *Client) post(ctx context.Context, url string, header http.Header, payload []byte) ([]byte, time.Duration, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, -1, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		return body, 0, err
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("%s returned %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			return nil, time.Duration(seconds) * time.Second, err
+		}
+		return nil, 0, err
+	case resp.StatusCode >= 500:
+		return nil, 0, err
+	default:
+		return nil, -1, err
+	}
+}
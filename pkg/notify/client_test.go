@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer answers the n-th request with statuses[n], repeating the
+// last status, and counts the requests
+func statusServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1)) - 1
+		if n >= len(statuses) {
+			n = len(statuses) - 1
+		}
+		for name, values := range header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(statuses[n])
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestPostRetriesServerErrors(t *testing.T) {
+	server, requests := statusServer(t, nil, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+	c := Client{Retries: 3, Backoff: 20 * time.Millisecond}
+
+	start := time.Now()
+	if err := c.Post(context.Background(), server.URL, []byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *requests != 3 {
+		t.Errorf("got %d requests, want 3", *requests)
+	}
+	// The backoff doubles: 20ms, then 40ms
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("retried after %s, want a backoff of at least 60ms", elapsed)
+	}
+}
+
+func TestPostGivesUpAfterRetries(t *testing.T) {
+	server, requests := statusServer(t, nil, http.StatusInternalServerError)
+	c := Client{Retries: 2, Backoff: time.Millisecond}
+
+	if err := c.Post(context.Background(), server.URL, []byte(`{}`)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if *requests != 3 {
+		t.Errorf("got %d requests, want 3", *requests)
+	}
+}
+
+func TestPostHonorsRetryAfter(t *testing.T) {
+	server, requests := statusServer(t, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests, http.StatusOK)
+	// The backoff would outlast the context, so only Retry-After can
+	// let the retry through
+	c := Client{Retries: 1, Backoff: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := c.Post(ctx, server.URL, []byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *requests != 2 {
+		t.Errorf("got %d requests, want 2", *requests)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want the Retry-After of 1s", elapsed)
+	}
+}
+
+func TestPostDoesNotRetryClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound} {
+		server, requests := statusServer(t, nil, status)
+		c := Client{Retries: 3, Backoff: time.Millisecond}
+
+		if err := c.Post(context.Background(), server.URL, []byte(`{}`)); err == nil {
+			t.Errorf("status %d: expected an error", status)
+		}
+		if *requests != 1 {
+			t.Errorf("status %d: got %d requests, want 1", status, *requests)
+		}
+	}
+}
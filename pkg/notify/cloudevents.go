@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// ValidateCloudEvents validates the CloudEvents sink, if set
+func (e *Endpoint) ValidateCloudEvents() error {
+	if e.URL == "" {
+		return nil
+	}
+	return e.validate("cloudevents-url")
+}
+
+// SendCloudEvents posts a CloudEvent for each event of the summary, followed
+// by one for the summary, to the sink in the binary content mode
+func (c *Client) SendCloudEvents(ctx context.Context, e *Endpoint, summary *types.Summary) error {
+	var events []output.CloudEvent
+	for _, group := range summary.Groups {
+		for i, event := range group.Events {
+			events = append(events, output.EventCloudEvent(event, group.EventCluster(i), group.Key))
+		}
+	}
+	events = append(events, output.SummaryCloudEvent(summary))
+
+	for _, event := range events {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode CloudEvent %s: %v", event.ID, err)
+		}
+		header := e.header("application/json")
+		for name, value := range event.Attributes() {
+			header.Set("ce-"+name, value)
+		}
+		if _, err := c.PostWithHeader(ctx, e.apiURL(""), header, data); err != nil {
+			return fmt.Errorf("failed to send CloudEvent %s: %v", event.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// ValidateElasticsearch validates the Elasticsearch endpoint, if set
+func (e *Endpoint) ValidateElasticsearch() error {
+	if e.URL == "" {
+		return nil
+	}
+	return e.validate("es-url")
+}
+
+// bulkResponse is the part of the _bulk response reporting failed items
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// BulkIndex indexes the events of the summary into Elasticsearch
+func (c *Client) BulkIndex(ctx context.Context, e *Endpoint, index string, summary *types.Summary) error {
+	var payload bytes.Buffer
+	if err := output.WriteESBulk(&payload, summary, index); err != nil {
+		return err
+	}
+	if payload.Len() == 0 {
+		return nil
+	}
+
+	body, err := c.PostWithHeader(ctx, e.apiURL("/_bulk"), e.header("application/x-ndjson"), payload.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to index events: %v", err)
+	}
+
+	// _bulk reports failures of single documents in a successful response
+	var resp bulkResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse bulk response: %v", err)
+	}
+	if !resp.Errors {
+		return nil
+	}
+	failed := 0
+	var first string
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error == nil {
+				continue
+			}
+			failed++
+			if first == "" {
+				first = fmt.Sprintf("%s: %s: %s", result.ID, result.Error.Type, result.Error.Reason)
+			}
+		}
+	}
+	return fmt.Errorf("failed to index %d of %d events, e.g. %s", failed, len(resp.Items), first)
+}
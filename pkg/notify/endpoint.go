@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Endpoint is an HTTP endpoint the events are exported to
+type Endpoint struct {
+	// URL is the base URL of the service, to which the API path of the
+	// export is appended, or the full URL of the API if it has a path
+	URL string
+	// Headers are name=value pairs added to each request, e.g. for
+	// authentication
+	Headers []string
+}
+
+// validate validates the endpoint, flag naming its URL flag in errors
+func (e *Endpoint) validate(flag string) error {
+	u, err := url.Parse(e.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s: %s, must be an http or https URL", flag, e.URL)
+	}
+
+	for _, h := range e.Headers {
+		if name, _, ok := strings.Cut(h, "="); !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid header for %s: %q, must be name=value", flag, h)
+		}
+	}
+	return nil
+}
+
+// apiURL returns the URL to post to, appending path to a base URL
+func (e *Endpoint) apiURL(path string) string {
+	u, err := url.Parse(e.URL)
+	if err == nil && (u.Path == "" || u.Path == "/") {
+		return strings.TrimSuffix(e.URL, "/") + path
+	}
+	return e.URL
+}
+
+// header returns the request header with the endpoint's headers
+func (e *Endpoint) header(contentType string) http.Header {
+	header := http.Header{}
+	for _, h := range e.Headers {
+		name, value, _ := strings.Cut(h, "=")
+		header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	header.Set("Content-Type", contentType)
+	return header
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// ValidateLoki validates the Loki endpoint, if set
+func (e *Endpoint) ValidateLoki() error {
+	if e.URL == "" {
+		return nil
+	}
+	return e.validate("loki-url")
+}
+
+// PushLoki pushes the events of the summary to Loki
+func (c *Client) PushLoki(ctx context.Context, e *Endpoint, summary *types.Summary) error {
+	payload, err := output.LokiPush(summary)
+	if err != nil {
+		return err
+	}
+	if _, err := c.PostWithHeader(ctx, e.apiURL("/loki/api/v1/push"), e.header("application/json"), payload); err != nil {
+		return fmt.Errorf("failed to push to Loki: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// Validate validates the notification options
+func (o *Options) Validate() error {
+	if !o.Enabled() {
+		return nil
+	}
+
+	valid := false
+	for _, format := range Formats {
+		if o.Format == format {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid notify-format: %s, must be one of: %s", o.Format, strings.Join(Formats, ", "))
+	}
+
+	if o.URL != "" {
+		u, err := url.Parse(o.URL)
+		if err != nil {
+			return fmt.Errorf("invalid notify-webhook: %v", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid notify-webhook: %s, must be an http or https URL", o.URL)
+		}
+	}
+
+	if o.Retries < 0 {
+		return fmt.Errorf("invalid notify-retries: %d, must not be negative", o.Retries)
+	}
+	return nil
+}
+
+// Notify posts the summary to the webhook, or prints the payload to out in
+// dry-run mode
+func (o *Options) Notify(ctx context.Context, out io.Writer, summary *types.Summary) error {
+	payload, err := o.Payload(summary)
+	if err != nil {
+		return err
+	}
+
+	if o.DryRun {
+		_, err := fmt.Fprintf(out, "%s\n", payload)
+		return err
+	}
+
+	if err := o.Post(ctx, o.URL, payload); err != nil {
+		return fmt.Errorf("failed to send notification: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// OTLPProtocols lists the protocols of --otlp-protocol
+var OTLPProtocols = []string{"http/protobuf", "http/json"}
+
+// OTLPOptions configures the export of events as OTLP log records to a
+// collector
+type OTLPOptions struct {
+	Endpoint
+	Protocol string
+}
+
+// Validate validates the OTLP export options
+func (o *OTLPOptions) Validate() error {
+	if o.URL == "" {
+		return nil
+	}
+	if err := o.validate("otlp-endpoint"); err != nil {
+		return err
+	}
+
+	for _, protocol := range OTLPProtocols {
+		if o.Protocol == protocol {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid otlp-protocol: %s, must be one of: %s", o.Protocol, strings.Join(OTLPProtocols, ", "))
+}
+
+// ExportLogs posts the events of the summary to the collector as OTLP log
+// records observed at now
+func (c *Client) ExportLogs(ctx context.Context, o *OTLPOptions, summary *types.Summary, now time.Time) error {
+	var payload []byte
+	contentType := "application/x-protobuf"
+	if o.Protocol == "http/json" {
+		var err error
+		payload, err = output.OTLPLogsJSON(summary, now)
+		if err != nil {
+			return fmt.Errorf("failed to encode log records: %v", err)
+		}
+		contentType = "application/json"
+	} else {
+		payload = output.OTLPLogsProtobuf(summary, now)
+	}
+
+	if _, err := c.PostWithHeader(ctx, o.apiURL("/v1/logs"), o.header(contentType), payload); err != nil {
+		return fmt.Errorf("failed to export log records: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+const (
+	// maxGroups and maxObjects bound the size of the message
+	maxGroups  = 5
+	maxObjects = 3
+)
+
+// templateData is passed to --notify-template and used to build the
+// built-in payloads
+type templateData struct {
+	Summary *types.Summary
+	Title   string
+	// Groups are the groups with the most warnings
+	Groups []group
+}
+
+type group struct {
+	Key        string
+	Total      int
+	Warnings   int
+	Errors     int
+	TopReasons string
+	// Objects are the objects of the most recent warning events
+	Objects []object
+}
+
+type object struct {
+	Ref     string
+	URL     string
+	Reason  string
+	Message string
+}
+
+// Payload renders the request body for the summary
+func (o *Options) Payload(summary *types.Summary) ([]byte, error) {
+	data, err := o.templateData(summary)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.TemplateFile != "" {
+		content, err := os.ReadFile(o.TemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notify template: %v", err)
+		}
+		tmpl, err := template.New("notify").Funcs(templateFuncs).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notify template: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to execute notify template: %v", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	var payload interface{}
+	switch o.Format {
+	case "slack":
+		payload = slackPayload(data)
+	case "teams":
+		payload = teamsPayload(data)
+	default:
+		payload = genericPayload(data)
+	}
+	return json.Marshal(payload)
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value, e.g. to embed a message in a JSON body
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func (o *Options) templateData(summary *types.Summary) (*templateData, error) {
+	var objectURL *template.Template
+	if o.ObjectURL != "" {
+		var err error
+		objectURL, err = template.New("object-url").Parse(o.ObjectURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notify object URL: %v", err)
+		}
+	}
+
+	data := &templateData{
+		Summary: summary,
+		Title: fmt.Sprintf("Kubernetes events: %d warnings, %d errors in the last %s",
+			summary.Filtered.Warnings, summary.Filtered.Errors, summary.Until.Sub(summary.Since).Round(time.Second)),
+	}
+
+	groups := make([]types.Group, 0, len(summary.Groups))
+	for _, g := range summary.Groups {
+		if g.Warnings > 0 {
+			groups = append(groups, g)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Warnings > groups[j].Warnings })
+	if len(groups) > maxGroups {
+		groups = groups[:maxGroups]
+	}
+
+	for _, g := range groups {
+		ng := group{
+			Key:        g.Key,
+			Total:      g.Total,
+			Warnings:   g.Warnings,
+			Errors:     g.Errors,
+			TopReasons: output.FormatTopReasons(g.Reasons, 3),
+		}
+		for _, event := range recentWarnings(g.Events) {
+			ref := event.InvolvedObject
+			obj := object{
+				Ref:     fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name),
+				Reason:  event.Reason,
+				Message: event.Message,
+			}
+			if objectURL != nil {
+				var buf bytes.Buffer
+				if err := objectURL.Execute(&buf, ref); err != nil {
+					return nil, fmt.Errorf("failed to execute notify object URL: %v", err)
+				}
+				obj.URL = buf.String()
+			}
+			ng.Objects = append(ng.Objects, obj)
+		}
+		data.Groups = append(data.Groups, ng)
+	}
+	return data, nil
+}
+
+// recentWarnings returns the most recent warning events of distinct objects
+func recentWarnings(events []corev1.Event) []corev1.Event {
+	var warnings []corev1.Event
+	for _, event := range events {
+		if event.Type == "Warning" {
+			warnings = append(warnings, event)
+		}
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return types.EventTime(warnings[i]).After(types.EventTime(warnings[j]))
+	})
+
+	seen := make(map[corev1.ObjectReference]bool)
+	var recent []corev1.Event
+	for _, event := range warnings {
+		ref := corev1.ObjectReference{
+			Kind:      event.InvolvedObject.Kind,
+			Namespace: event.InvolvedObject.Namespace,
+			Name:      event.InvolvedObject.Name,
+		}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		recent = append(recent, event)
+		if len(recent) == maxObjects {
+			break
+		}
+	}
+	return recent
+}
+
+func totalsLine(summary *types.Summary) string {
+	return fmt.Sprintf("Total: %d (Warnings: %d, Errors: %d), Filtered: %d (Warnings: %d, Errors: %d)",
+		summary.Totals.Total, summary.Totals.Warnings, summary.Totals.Errors,
+		summary.Filtered.Total, summary.Filtered.Warnings, summary.Filtered.Errors)
+}
+
+func groupLine(g group) string {
+	return fmt.Sprintf("%d events, %d warnings, %d errors (%s)", g.Total, g.Warnings, g.Errors, g.TopReasons)
+}
+
+// slackPayload builds a Slack incoming webhook message with Block Kit
+// sections
+func slackPayload(data *templateData) map[string]interface{} {
+	blocks := []map[string]interface{}{
+		{"type": "header", "text": map[string]interface{}{"type": "plain_text", "text": data.Title}},
+		{"type": "section", "text": slackText(slackEscaper.Replace(totalsLine(data.Summary)))},
+	}
+	for _, g := range data.Groups {
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%s*\n%s", slackEscaper.Replace(g.Key), slackEscaper.Replace(groupLine(g)))
+		for _, obj := range g.Objects {
+			ref := slackEscaper.Replace(obj.Ref)
+			if obj.URL != "" {
+				ref = fmt.Sprintf("<%s|%s>", obj.URL, ref)
+			}
+			fmt.Fprintf(&b, "\n• %s: %s", ref, slackEscaper.Replace(output.SingleLine(obj.Message)))
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "section", "text": slackText(b.String())})
+	}
+	return map[string]interface{}{
+		"text":   data.Title,
+		"blocks": blocks,
+	}
+}
+
+func slackText(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "mrkdwn", "text": text}
+}
+
+// slackEscaper escapes the control characters of Slack's mrkdwn
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// teamsPayload builds a Microsoft Teams message with an Adaptive Card
+func teamsPayload(data *templateData) map[string]interface{} {
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": data.Title, "size": "Large", "weight": "Bolder", "wrap": true},
+		{"type": "TextBlock", "text": totalsLine(data.Summary), "wrap": true},
+	}
+	for _, g := range data.Groups {
+		body = append(body,
+			map[string]interface{}{"type": "TextBlock", "text": g.Key, "weight": "Bolder", "wrap": true, "separator": true},
+			map[string]interface{}{"type": "TextBlock", "text": groupLine(g), "wrap": true})
+		for _, obj := range g.Objects {
+			ref := obj.Ref
+			if obj.URL != "" {
+				ref = fmt.Sprintf("[%s](%s)", obj.Ref, obj.URL)
+			}
+			body = append(body, map[string]interface{}{
+				"type": "TextBlock", "text": fmt.Sprintf("- %s: %s", ref, output.SingleLine(obj.Message)), "wrap": true,
+			})
+		}
+	}
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+// genericPayload is a plain JSON document for custom receivers
+func genericPayload(data *templateData) map[string]interface{} {
+	type genericObject struct {
+		Ref     string `json:"ref"`
+		URL     string `json:"url,omitempty"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+	type genericGroup struct {
+		Key        string          `json:"key"`
+		Total      int             `json:"total"`
+		Warnings   int             `json:"warnings"`
+		Errors     int             `json:"errors"`
+		TopReasons string          `json:"topReasons"`
+		Objects    []genericObject `json:"objects,omitempty"`
+	}
+
+	groups := []genericGroup{}
+	for _, g := range data.Groups {
+		gg := genericGroup{Key: g.Key, Total: g.Total, Warnings: g.Warnings, Errors: g.Errors, TopReasons: g.TopReasons}
+		for _, obj := range g.Objects {
+			gg.Objects = append(gg.Objects, genericObject(obj))
+		}
+		groups = append(groups, gg)
+	}
+	return map[string]interface{}{
+		"title":      data.Title,
+		"totals":     data.Summary.Totals,
+		"filtered":   data.Summary.Filtered,
+		"since":      data.Summary.Since,
+		"until":      data.Summary.Until,
+		"thresholds": data.Summary.Thresholds,
+		"groups":     groups,
+	}
+}
@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+func testSummary() *types.Summary {
+	until := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	group := &types.GroupSummary{
+		Total:    2,
+		Warnings: 2,
+		Errors:   1,
+		Types:    map[string]int{"Warning": 2},
+		Reasons:  map[string]int{"BackOff": 1, "Unhealthy": 1},
+	}
+	group.AddEvent(corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-0"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting\nfailed container",
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(until.Add(-time.Minute)),
+	}, "")
+	group.AddEvent(corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-1"},
+		Reason:         "Unhealthy",
+		Message:        "Readiness probe failed",
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(until.Add(-2 * time.Minute)),
+	}, "")
+	return &types.Summary{
+		Totals:   types.Totals{Total: 5, Warnings: 2, Errors: 1},
+		Filtered: types.Totals{Total: 2, Warnings: 2, Errors: 1},
+		Since:    until.Add(-15 * time.Minute),
+		Until:    until,
+		Groups:   []types.Group{{Key: "namespace=shop", GroupSummary: group}},
+	}
+}
+
+// notifyServer posts the notification to a test server and returns the
+// request body it received
+func notifyServer(t *testing.T, o *Options) string {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("got content type %q, want application/json", got)
+		}
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	o.URL = server.URL
+	if err := o.Notify(context.Background(), io.Discard, testSummary()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(body)
+}
+
+const testTitle = "Kubernetes events: 2 warnings, 1 errors in the last 15m0s"
+
+func TestPayloads(t *testing.T) {
+	objectURL := "https://dash.example.com/{{.Namespace}}/{{.Kind}}/{{.Name}}"
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{
+			format: "slack",
+			want: []string{
+				`"text":"` + testTitle + `"`,
+				`"type":"header"`,
+				`*namespace=shop*\n2 events, 2 warnings, 1 errors`,
+				`• \u003chttps://dash.example.com/shop/Pod/web-0|Pod shop/web-0\u003e: Back-off restarting failed container`,
+			},
+		},
+		{
+			format: "teams",
+			want: []string{
+				`"contentType":"application/vnd.microsoft.card.adaptive"`,
+				`"type":"AdaptiveCard"`,
+				`"text":"` + testTitle + `"`,
+				`"text":"- [Pod shop/web-1](https://dash.example.com/shop/Pod/web-1): Readiness probe failed"`,
+			},
+		},
+		{
+			format: "generic",
+			want: []string{
+				`"title":"` + testTitle + `"`,
+				`"filtered":{"total":2,"warnings":2,"errors":1}`,
+				`"key":"namespace=shop"`,
+				`{"ref":"Pod shop/web-0","url":"https://dash.example.com/shop/Pod/web-0","reason":"BackOff","message":"Back-off restarting\nfailed container"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			body := notifyServer(t, &Options{Format: tt.format, ObjectURL: objectURL})
+			if !json.Valid([]byte(body)) {
+				t.Fatalf("payload is not valid JSON: %s", body)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("payload does not contain %s:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestPayloadWithoutObjectURL(t *testing.T) {
+	body := notifyServer(t, &Options{Format: "slack"})
+	if !strings.Contains(body, `• Pod shop/web-0: Back-off`) {
+		t.Errorf("payload does not link objects plainly:\n%s", body)
+	}
+}
+
+func TestTemplatePayload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "notify.tmpl")
+	content := `{"summary": {{ json .Title }}, "groups": [{{ range $i, $g := .Groups }}{{ if $i }},{{ end }}` +
+		`{"key": {{ json $g.Key }}, "first": {{ json (index $g.Objects 0).URL }}}{{ end }}]}`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	body := notifyServer(t, &Options{Format: "slack", TemplateFile: file, ObjectURL: "https://dash.example.com/{{.Name}}"})
+	want := `{"summary": "` + testTitle + `", "groups": [{"key": "namespace=shop", "first": "https://dash.example.com/web-0"}]}`
+	if body != want {
+		t.Errorf("got payload\n%s\nwant\n%s", body, want)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "notify.tmpl")
+	if err := os.WriteFile(file, []byte(`{{ .Missing`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"missing template", Options{TemplateFile: filepath.Join(t.TempDir(), "none")}, "failed to read notify template"},
+		{"bad template", Options{TemplateFile: file}, "failed to parse notify template"},
+		{"bad object URL", Options{ObjectURL: "{{ .Name"}, "failed to parse notify object URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.opts.Payload(testSummary())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("dry run posted the notification")
+	}))
+	defer server.Close()
+
+	var out strings.Builder
+	o := &Options{URL: server.URL, Format: "generic", DryRun: true}
+	if err := o.Notify(context.Background(), &out, testSummary()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), `{"filtered":`) || !strings.HasSuffix(out.String(), "}\n") {
+		t.Errorf("dry run did not print the payload:\n%s", out.String())
+	}
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"time"
+)
+
+// Formats lists the payload formats of --notify-format
+var Formats = []string{"slack", "teams", "generic"}
+
+// Options configures the webhook notification sent with the summary
+type Options struct {
+	// URL is the webhook to post to; no notification is sent if empty,
+	// unless DryRun is set
+	URL    string
+	Format string
+	// TemplateFile is a Go template rendering the request body, replacing
+	// the payload of Format
+	TemplateFile string
+	// ObjectURL is a Go template over an object reference (.Kind,
+	// .Namespace, .Name) linking to the object, e.g. in a dashboard
+	ObjectURL string
+	// DryRun prints the payload instead of posting it
+	DryRun bool
+
+	Client
+}
+
+// Client posts JSON payloads, retrying failed requests
+type Client struct {
+	// Retries is the number of retries after a failed post, waiting Backoff
+	// before the first retry and twice as long before each next one
+	Retries int
+	Backoff time.Duration
+	Timeout time.Duration
+}
+
+// Enabled reports whether a notification should be sent or printed
+func (o *Options) Enabled() bool {
+	return o.URL != "" || o.DryRun
+}
@@ -0,0 +1,153 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// GitHubAnnotationsFormatter writes a ::warning or ::error workflow command
+// for each group with warning or error events, and for each breached
+// --fail-on rule, so they show up in the GitHub Actions checks UI
+type GitHubAnnotationsFormatter struct {
+	out io.Writer
+}
+
+func (f *GitHubAnnotationsFormatter) Format(summary *types.Summary) error {
+	for _, group := range summary.Groups {
+		if group.Warnings == 0 {
+			continue
+		}
+		command := "warning"
+		if group.Errors > 0 {
+			command = "error"
+		}
+		if _, err := fmt.Fprintf(f.out, "::%s title=%s::%s\n", command,
+			githubPropertyEscaper.Replace("Kubernetes events: "+group.Key),
+			githubDataEscaper.Replace(groupDescription(group))); err != nil {
+			return err
+		}
+	}
+
+	for _, result := range summary.Thresholds {
+		if !result.Breached {
+			continue
+		}
+		if _, err := fmt.Fprintf(f.out, "::error title=%s::%s\n",
+			githubPropertyEscaper.Replace("Event threshold breached"),
+			githubDataEscaper.Replace(result.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GitLabCodeQualityFormatter writes a GitLab Code Quality report with an
+// issue for each group with warning or error events, and for each breached
+// --fail-on rule
+type GitLabCodeQualityFormatter struct {
+	out io.Writer
+}
+
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+func (f *GitLabCodeQualityFormatter) Format(summary *types.Summary) error {
+	issues := []codeQualityIssue{}
+	for _, group := range summary.Groups {
+		if group.Warnings == 0 {
+			continue
+		}
+		severity := "major"
+		if group.Errors > 0 {
+			severity = "critical"
+		}
+		issues = append(issues, codeQualityIssue{
+			Description: group.Key + ": " + groupDescription(group),
+			CheckName:   "kubectl-event-summary",
+			Fingerprint: fingerprint("group", group.Key),
+			Severity:    severity,
+			// Events have no source file, the group key locates them instead
+			Location: codeQualityLocation{Path: group.Key, Lines: codeQualityLines{Begin: 1}},
+		})
+	}
+
+	for _, result := range summary.Thresholds {
+		if !result.Breached {
+			continue
+		}
+		issues = append(issues, codeQualityIssue{
+			Description: "Event threshold breached: " + result.String(),
+			CheckName:   "kubectl-event-summary/threshold",
+			Fingerprint: fingerprint("threshold", result.Rule),
+			Severity:    "blocker",
+			Location:    codeQualityLocation{Path: "thresholds", Lines: codeQualityLines{Begin: 1}},
+		})
+	}
+
+	enc := json.NewEncoder(f.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
+
+// groupDescription summarizes the warning events of a group, e.g.
+// "3 warnings, 1 errors (BackOff=2, Failed=1): Pod default/web-0: Back-off ..."
+func groupDescription(group types.Group) string {
+	description := fmt.Sprintf("%d warnings, %d errors (%s)", group.Warnings, group.Errors,
+		formatCounts(topReasons(group.Reasons, 3)))
+	if event, ok := latestWarning(group.Events); ok {
+		description += fmt.Sprintf(": %s %s/%s: %s", event.InvolvedObject.Kind,
+			event.InvolvedObject.Namespace, event.InvolvedObject.Name, event.Message)
+	}
+	return description
+}
+
+// latestWarning returns the most recent warning event
+func latestWarning(events []corev1.Event) (corev1.Event, bool) {
+	var latest corev1.Event
+	found := false
+	for _, event := range events {
+		if event.Type != "Warning" {
+			continue
+		}
+		if !found || types.EventTime(event).After(types.EventTime(latest)) {
+			latest, found = event, true
+		}
+	}
+	return latest, found
+}
+
+// fingerprint identifies an issue across runs so that GitLab can tell new
+// issues from resolved ones
+func fingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// githubDataEscaper and githubPropertyEscaper escape workflow command
+// messages and property values
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
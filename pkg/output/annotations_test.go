@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAnnotationsGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{name: "annotations.github.golden", format: "github-annotations"},
+		{name: "codequality.json.golden", format: "gitlab-codequality"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, tt.format, Options{}, thresholdSummary())
+		})
+	}
+}
+
+func TestGitHubAnnotationsEscaping(t *testing.T) {
+	var out bytes.Buffer
+	f := &GitHubAnnotationsFormatter{out: &out}
+	if err := f.Format(thresholdSummary()); err != nil {
+		t.Fatal(err)
+	}
+
+	// One workflow command per line: the groups with warnings and the
+	// breached rule
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d commands, want 3:\n%s", len(lines), out.String())
+	}
+	unescaper := strings.NewReplacer("%0D", "\r", "%0A", "\n", "%3A", ":", "%2C", ",", "%25", "%")
+	for _, line := range lines {
+		command, rest, ok := strings.Cut(line, " title=")
+		if !ok || (command != "::warning" && command != "::error") {
+			t.Fatalf("not a workflow command: %s", line)
+		}
+		// Properties end at the first "::", so titles hold no raw colons or
+		// commas
+		title, message, ok := strings.Cut(rest, "::")
+		if !ok || strings.ContainsAny(title, ":,") {
+			t.Errorf("title not escaped: %s", line)
+		}
+		if strings.ContainsAny(message, "\r\n") {
+			t.Errorf("message not escaped: %s", line)
+		}
+		if strings.Contains(message, "100%") && !strings.Contains(message, "100%25") {
+			t.Errorf("percent sign not escaped: %s", line)
+		}
+	}
+
+	unhealthy := lines[1]
+	title, message, _ := strings.Cut(strings.TrimPrefix(unhealthy, "::warning title="), "::")
+	if got, want := unescaper.Replace(title), "Kubernetes events: namespace=shop,reason=Unhealthy"; got != want {
+		t.Errorf("got title %q, want %q", got, want)
+	}
+	if got, want := unescaper.Replace(message), "1 warnings, 0 errors (Unhealthy=1): Pod shop/web-1: Readiness probe failed: | 503 | 100% ]]>\nbody: <h1>down</h1>"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+}
+
+func TestGitLabCodeQuality(t *testing.T) {
+	var out bytes.Buffer
+	f := &GitLabCodeQualityFormatter{out: &out}
+	if err := f.Format(thresholdSummary()); err != nil {
+		t.Fatal(err)
+	}
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(out.Bytes(), &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3", len(issues))
+	}
+	fingerprints := make(map[string]bool)
+	for _, issue := range issues {
+		fingerprints[issue.Fingerprint] = true
+	}
+	if len(fingerprints) != 3 {
+		t.Errorf("issues share fingerprints: %+v", issues)
+	}
+	if issues[0].Severity != "critical" || issues[1].Severity != "major" || issues[2].Severity != "blocker" {
+		t.Errorf("got severities %s, %s and %s", issues[0].Severity, issues[1].Severity, issues[2].Severity)
+	}
+
+	// No warnings is an empty report, not null
+	out.Reset()
+	summary := testSummary()
+	summary.Groups = summary.Groups[2:]
+	if err := f.Format(summary); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "[]" {
+		t.Errorf("got %s, want []", got)
+	}
+}
@@ -0,0 +1,140 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// CloudEvents types of Kubernetes events, suffixed with the event reason,
+// and of the summary
+const (
+	CloudEventTypePrefix  = "io.k8s.event."
+	CloudEventSummaryType = "io.github.nareshku.eventsummary.v1alpha1.summary"
+)
+
+// CloudEvent is a CloudEvents 1.0 event with JSON data
+type CloudEvent struct {
+	ID      string
+	Source  string
+	Type    string
+	Subject string
+	Time    time.Time
+	// Extensions are extension context attributes, e.g. severity
+	Extensions map[string]string
+	Data       interface{}
+}
+
+// MarshalJSON encodes the event in the structured content mode
+func (e CloudEvent) MarshalJSON() ([]byte, error) {
+	attrs := map[string]interface{}{
+		"specversion":     "1.0",
+		"id":              e.ID,
+		"source":          e.Source,
+		"type":            e.Type,
+		"datacontenttype": "application/json",
+		"data":            e.Data,
+	}
+	if e.Subject != "" {
+		attrs["subject"] = e.Subject
+	}
+	if !e.Time.IsZero() {
+		attrs["time"] = e.Time.UTC().Format(time.RFC3339)
+	}
+	for name, value := range e.Extensions {
+		attrs[name] = value
+	}
+	return json.Marshal(attrs)
+}
+
+// Attributes returns the context attributes of the event, e.g. as ce-
+// headers of the binary content mode
+func (e CloudEvent) Attributes() map[string]string {
+	attrs := map[string]string{
+		"specversion": "1.0",
+		"id":          e.ID,
+		"source":      e.Source,
+		"type":        e.Type,
+	}
+	if e.Subject != "" {
+		attrs["subject"] = e.Subject
+	}
+	if !e.Time.IsZero() {
+		attrs["time"] = e.Time.UTC().Format(time.RFC3339)
+	}
+	for name, value := range e.Extensions {
+		attrs[name] = value
+	}
+	return attrs
+}
+
+// CloudEventsFormatter writes one structured-mode CloudEvent per line for
+// each event, as soon as it passes the filters, followed by one for the
+// summary
+type CloudEventsFormatter struct {
+	out io.Writer
+}
+
+func (f *CloudEventsFormatter) StreamEvent(event corev1.Event, cluster, groupKey string) error {
+	return json.NewEncoder(f.out).Encode(EventCloudEvent(event, cluster, groupKey))
+}
+
+func (f *CloudEventsFormatter) Format(summary *types.Summary) error {
+	enc := json.NewEncoder(f.out)
+	// Events still held by the summary were not streamed
+	for _, group := range summary.Groups {
+		for i, event := range group.Events {
+			if err := enc.Encode(EventCloudEvent(event, group.EventCluster(i), group.Key)); err != nil {
+				return err
+			}
+		}
+	}
+	return enc.Encode(SummaryCloudEvent(summary))
+}
+
+// EventCloudEvent wraps a Kubernetes event fetched from cluster, which may
+// be empty. Its source identifies the involved object, e.g.
+// /clusters/prod/namespaces/default/pod/web-0, and its type is
+// CloudEventTypePrefix followed by the reason.
+func EventCloudEvent(event corev1.Event, cluster, groupKey string) CloudEvent {
+	obj := event.InvolvedObject
+	var source strings.Builder
+	if cluster != "" {
+		fmt.Fprintf(&source, "/clusters/%s", cluster)
+	}
+	if obj.Namespace != "" {
+		fmt.Fprintf(&source, "/namespaces/%s", obj.Namespace)
+	}
+	fmt.Fprintf(&source, "/%s/%s", strings.ToLower(obj.Kind), obj.Name)
+
+	return CloudEvent{
+		ID:      fmt.Sprintf("%s-%d", event.UID, event.Count),
+		Source:  source.String(),
+		Type:    CloudEventTypePrefix + event.Reason,
+		Subject: obj.Kind + "/" + obj.Name,
+		Time:    types.EventTime(event),
+		Extensions: map[string]string{
+			"severity": string(types.EventSeverity(event)),
+			"group":    groupKey,
+		},
+		Data: event,
+	}
+}
+
+// SummaryCloudEvent wraps the summary document, without the events of its
+// groups
+func SummaryCloudEvent(summary *types.Summary) CloudEvent {
+	return CloudEvent{
+		ID:     fmt.Sprintf("summary-%d", summary.Until.UnixNano()),
+		Source: "/kubectl-event-summary",
+		Type:   CloudEventSummaryType,
+		Time:   summary.Until,
+		Data:   summary.WithoutEvents(),
+	}
+}
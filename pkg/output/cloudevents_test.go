@@ -0,0 +1,87 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestCloudEventsGolden(t *testing.T) {
+	formatGolden(t, "events.cloudevents.golden", "cloudevents", Options{}, testSummary())
+}
+
+func TestCloudEventsAttributes(t *testing.T) {
+	summary := multiClusterSummary()
+	summary.Groups = inCluster(summary.Groups, "prod")
+
+	var out bytes.Buffer
+	f := &CloudEventsFormatter{out: &out}
+	if err := f.Format(summary); err != nil {
+		t.Fatal(err)
+	}
+
+	// Attribute names are lower-case letters and digits, as the
+	// specification requires
+	attributeName := regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+	var events []map[string]interface{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		for name := range event {
+			if !attributeName.MatchString(name) {
+				t.Errorf("invalid attribute name %q", name)
+			}
+		}
+		for _, required := range []string{"specversion", "id", "source", "type"} {
+			if event[required] == "" || event[required] == nil {
+				t.Errorf("event lacks %s: %v", required, event)
+			}
+		}
+		events = append(events, event)
+	}
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 4 and the summary", len(events))
+	}
+
+	first := events[0]
+	want := map[string]interface{}{
+		"id":       "uid-web-0-BackOff-5",
+		"source":   "/clusters/prod/namespaces/shop/pod/web-0",
+		"type":     "io.k8s.event.BackOff",
+		"subject":  "Pod/web-0",
+		"time":     "2024-05-01T10:14:00Z",
+		"severity": "error",
+		"group":    "namespace=shop,reason=BackOff",
+	}
+	for name, value := range want {
+		if first[name] != value {
+			t.Errorf("got %s %v, want %v", name, first[name], value)
+		}
+	}
+
+	last := events[4]
+	if last["type"] != CloudEventSummaryType || last["time"] != "2024-05-01T10:15:00Z" {
+		t.Errorf("got last event %v %v, want the summary", last["type"], last["time"])
+	}
+	data := last["data"].(map[string]interface{})
+	if clusters := data["clusters"].([]interface{}); len(clusters) != 2 {
+		t.Errorf("got clusters %v in the summary", clusters)
+	}
+
+	// The binary mode headers carry the same attributes, without the data
+	event := EventCloudEvent(summary.Groups[0].Events[0], "prod", summary.Groups[0].Key)
+	attrs := event.Attributes()
+	for name, value := range want {
+		if attrs[name] != value {
+			t.Errorf("binary mode: got %s %q, want %v", name, attrs[name], value)
+		}
+	}
+	if _, ok := attrs["data"]; ok || attrs["specversion"] != "1.0" {
+		t.Errorf("binary mode: got attributes %v", attrs)
+	}
+}
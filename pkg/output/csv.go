@@ -0,0 +1,108 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// CSVFormatter writes one row per event, or one row per group in compact
+// mode, as comma- or tab-separated values
+type CSVFormatter struct {
+	out   io.Writer
+	comma rune
+	opts  Options
+}
+
+func (f *CSVFormatter) Format(summary *types.Summary) error {
+	w := csv.NewWriter(f.out)
+	w.Comma = f.comma
+
+	levels := groupLevels(f.opts.GroupBy)
+	var headers []string
+	for _, level := range levels {
+		headers = append(headers, "group_"+level)
+	}
+	if f.opts.Compact {
+		headers = append(headers, "total", "warnings", "errors", "top_reasons")
+	} else {
+		headers = append(headers, "last_seen", "type", "reason", "kind", "namespace", "name", "count", "message")
+	}
+	if !f.opts.NoHeaders {
+		if err := w.Write(headers); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range summary.Groups {
+		keyColumns := SplitGroupKey(group.Key, levels)
+		if f.opts.Compact {
+			row := append(keyColumns,
+				fmt.Sprint(group.Total),
+				fmt.Sprint(group.Warnings),
+				fmt.Sprint(group.Errors),
+				formatCounts(topReasons(group.Reasons, 3)))
+			if err := w.Write(row); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, event := range group.Events {
+			lastSeen := ""
+			if t := types.EventTime(event); !t.IsZero() {
+				lastSeen = t.UTC().Format(time.RFC3339)
+			}
+			row := append(append([]string{}, keyColumns...),
+				lastSeen,
+				event.Type,
+				event.Reason,
+				event.InvolvedObject.Kind,
+				event.InvolvedObject.Namespace,
+				event.InvolvedObject.Name,
+				fmt.Sprint(event.Count),
+				event.Message)
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// groupLevels returns the levels of a --group-by value
+func groupLevels(groupBy string) []string {
+	if groupBy == "" {
+		return nil
+	}
+	return strings.Split(groupBy, ",")
+}
+
+// SplitGroupKey splits a "level=value,level=value" group key into its
+// values. Values may themselves contain commas, so each value runs up to the
+// next ",level=" separator rather than the next comma.
+func SplitGroupKey(key string, levels []string) []string {
+	values := make([]string, len(levels))
+	rest := key
+	for i, level := range levels {
+		rest = strings.TrimPrefix(rest, level+"=")
+		if i == len(levels)-1 {
+			values[i] = rest
+			break
+		}
+		end := strings.Index(rest, ","+levels[i+1]+"=")
+		if end < 0 {
+			values[i] = rest
+			break
+		}
+		values[i] = rest[:end]
+		rest = rest[end+1:]
+	}
+	return values
+}
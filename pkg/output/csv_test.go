@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestCSVGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		opts   Options
+	}{
+		{name: "events.csv.golden", format: "csv", opts: Options{GroupBy: "namespace,reason"}},
+		{name: "groups.csv.golden", format: "csv", opts: Options{GroupBy: "namespace,reason", Compact: true}},
+		{name: "events.tsv.golden", format: "tsv", opts: Options{GroupBy: "namespace,reason", NoHeaders: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, tt.format, tt.opts, testSummary())
+		})
+	}
+}
+
+func TestCSVEscaping(t *testing.T) {
+	summary := testSummary()
+	for _, comma := range []rune{',', '\t'} {
+		var out bytes.Buffer
+		f := &CSVFormatter{out: &out, comma: comma, opts: Options{GroupBy: "namespace,reason"}}
+		if err := f.Format(summary); err != nil {
+			t.Fatal(err)
+		}
+
+		// Quotes, separators and line breaks in messages survive a round trip
+		r := csv.NewReader(&out)
+		r.Comma = comma
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("%q: %v", comma, err)
+		}
+		var messages []string
+		for _, record := range records[1:] {
+			messages = append(messages, record[len(record)-1])
+		}
+		var want []string
+		for _, group := range summary.Groups {
+			for _, event := range group.Events {
+				want = append(want, event.Message)
+			}
+		}
+		if !reflect.DeepEqual(messages, want) {
+			t.Errorf("%q: got messages %q, want %q", comma, messages, want)
+		}
+	}
+}
+
+func TestSplitGroupKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		levels []string
+		want   []string
+	}{
+		{key: "namespace=shop,reason=BackOff", levels: []string{"namespace", "reason"}, want: []string{"shop", "BackOff"}},
+		// Values may hold commas and equal signs
+		{key: "reason=a,b=c,type=Warning", levels: []string{"reason", "type"}, want: []string{"a,b=c", "Warning"}},
+		{key: "namespace=", levels: []string{"namespace"}, want: []string{""}},
+		{key: "all", levels: nil, want: []string{}},
+	}
+	for _, tt := range tests {
+		if got := SplitGroupKey(tt.key, tt.levels); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
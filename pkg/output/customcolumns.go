@@ -0,0 +1,120 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// column is a single NAME:JSONPATH entry of a custom-columns spec
+type column struct {
+	header string
+	parser *jsonpath.JSONPath
+}
+
+// CustomColumnsFormatter prints one row per event with user-defined columns,
+// using the same spec syntax as kubectl get -o custom-columns
+type CustomColumnsFormatter struct {
+	out       io.Writer
+	columns   []column
+	noHeaders bool
+}
+
+// parseCustomColumns parses a spec such as
+// "NAME:.involvedObject.name,REASON:.reason"
+func parseCustomColumns(spec string) ([]column, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("custom-columns format specified but no custom columns given")
+	}
+
+	var columns []column
+	for _, part := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(part, ":")
+		if !ok || header == "" || path == "" {
+			return nil, fmt.Errorf("unexpected custom-columns spec: %s, expected <header>:<json-path-expr>", part)
+		}
+		expr, err := relaxedJSONPathExpression(path)
+		if err != nil {
+			return nil, err
+		}
+		parser := jsonpath.New(header).AllowMissingKeys(true)
+		if err := parser.Parse(expr); err != nil {
+			return nil, fmt.Errorf("invalid custom-columns path %q: %v", path, err)
+		}
+		columns = append(columns, column{header: header, parser: parser})
+	}
+	return columns, nil
+}
+
+// relaxedJSONPathExpression accepts ".field", "field" and "{.field}" forms
+// like kubectl does
+func relaxedJSONPathExpression(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "{") {
+		if !strings.HasSuffix(path, "}") {
+			return "", fmt.Errorf("unmatched brace in custom-columns path %q", path)
+		}
+		path = path[1 : len(path)-1]
+	}
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return "{" + path + "}", nil
+}
+
+func (f *CustomColumnsFormatter) Format(summary *types.Summary) error {
+	headers := make([]string, len(f.columns))
+	for i, col := range f.columns {
+		headers[i] = col.header
+	}
+
+	var rows [][]string
+	for _, group := range summary.Groups {
+		for i := range group.Events {
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&group.Events[i])
+			if err != nil {
+				return fmt.Errorf("failed to convert event: %v", err)
+			}
+			row := make([]string, len(f.columns))
+			for j, col := range f.columns {
+				row[j], err = evaluateColumn(col, obj)
+				if err != nil {
+					return err
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return writeTable(f.out, headers, rows, f.noHeaders)
+}
+
+// evaluateColumn returns the column value, or <none> when the path matches nothing
+func evaluateColumn(col column, obj map[string]interface{}) (string, error) {
+	results, err := col.parser.FindResults(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate column %s: %v", col.header, err)
+	}
+
+	var values []string
+	for _, result := range results {
+		for _, value := range result {
+			var buf bytes.Buffer
+			if err := col.parser.PrintResults(&buf, []reflect.Value{value}); err != nil {
+				return "", err
+			}
+			values = append(values, SingleLine(buf.String()))
+		}
+	}
+	if len(values) == 0 {
+		return "<none>", nil
+	}
+	return strings.Join(values, ","), nil
+}
@@ -0,0 +1,129 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/term"
+	"k8s.io/cli-runtime/pkg/printers"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// ANSI colors of the wide diff output
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorReset = "\x1b[0m"
+)
+
+// ColorEnabled reports whether output to out should be colored: out must be
+// a terminal and NO_COLOR unset
+func ColorEnabled(out io.Writer) bool {
+	if _, noColor := os.LookupEnv("NO_COLOR"); noColor {
+		return false
+	}
+	f, ok := out.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// WriteDiff writes the diff as a table of group deltas (wide), or as a
+// JSON or YAML document. Wide output marks new groups with "+", removed
+// groups with "-" and changed groups with "~"; with color, rows where
+// warnings or errors grew are red and rows where they shrank are green.
+func WriteDiff(out io.Writer, diff *types.Diff, format string, color bool) error {
+	switch format {
+	case "json", "yaml":
+		obj, err := toUnstructured(diff)
+		if err != nil {
+			return err
+		}
+		var printer printers.ResourcePrinter = &printers.JSONPrinter{}
+		if format == "yaml" {
+			printer = &printers.YAMLPrinter{}
+		}
+		return printer.PrintObj(obj, out)
+	}
+
+	fmt.Fprintf(out, "\nBefore: %s (Total: %d, Warnings: %d, Errors: %d)\n",
+		diffWindow(diff.Before), diff.Before.Total, diff.Before.Warnings, diff.Before.Errors)
+	fmt.Fprintf(out, "After:  %s (Total: %d, Warnings: %d, Errors: %d)\n",
+		diffWindow(diff.After), diff.After.Total, diff.After.Warnings, diff.After.Errors)
+	if len(diff.NewReasons) > 0 {
+		fmt.Fprintf(out, "New reasons: %s\n", strings.Join(diff.NewReasons, ", "))
+	}
+	fmt.Fprintln(out, "---")
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "  GROUP\tTOTAL\tWARNINGS\tERRORS\tNEW REASONS")
+	for _, group := range diff.Groups {
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t%s\n",
+			diffMarker(group.Status), group.Key,
+			diffCount(group.Before.Total, group.After.Total),
+			diffCount(group.Before.Warnings, group.After.Warnings),
+			diffCount(group.Before.Errors, group.After.Errors),
+			strings.Join(group.NewReasons, ","))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Color whole lines after aligning, as escape codes would skew the columns
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, line := range lines {
+		if color && i > 0 && i <= len(diff.Groups) {
+			if c := diffColor(diff.Groups[i-1]); c != "" {
+				line = c + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+			}
+		}
+		if _, err := io.WriteString(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffWindow(window types.DiffWindow) string {
+	s := fmt.Sprintf("%s - %s", window.Since.Format(time.RFC3339), window.Until.Format(time.RFC3339))
+	if window.Snapshot != "" {
+		s += " from " + window.Snapshot
+	}
+	return s
+}
+
+func diffMarker(status types.DiffStatus) string {
+	switch status {
+	case types.DiffNew:
+		return "+"
+	case types.DiffRemoved:
+		return "-"
+	case types.DiffChanged:
+		return "~"
+	}
+	return " "
+}
+
+// diffCount formats a count in both windows, e.g. "3 -> 7 (+4)"
+func diffCount(before, after int) string {
+	if before == after {
+		return fmt.Sprint(after)
+	}
+	return fmt.Sprintf("%d -> %d (%+d)", before, after, after-before)
+}
+
+// diffColor returns red when warnings or errors grew and green when they shrank
+func diffColor(group types.GroupDiff) string {
+	switch {
+	case group.Delta.Errors > 0 || group.Delta.Warnings > 0:
+		return colorRed
+	case group.Delta.Errors < 0 || group.Delta.Warnings < 0:
+		return colorGreen
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// DefaultESIndex is the Elasticsearch index events are written to
+const DefaultESIndex = "kube-events"
+
+// ESBulkFormatter writes the events of the summary as an Elasticsearch
+// _bulk request
+type ESBulkFormatter struct {
+	out   io.Writer
+	index string
+}
+
+func (f *ESBulkFormatter) Format(summary *types.Summary) error {
+	return WriteESBulk(f.out, summary, f.index)
+}
+
+// esDocument is the document indexed for an event
+type esDocument struct {
+	Timestamp      time.Time         `json:"@timestamp"`
+	Severity       types.Severity    `json:"severity"`
+	Group          string            `json:"group"`
+	Cluster        string            `json:"cluster,omitempty"`
+	Type           string            `json:"type"`
+	Reason         string            `json:"reason"`
+	Message        string            `json:"message"`
+	Count          int32             `json:"count"`
+	FirstTimestamp *time.Time        `json:"firstTimestamp,omitempty"`
+	LastTimestamp  *time.Time        `json:"lastTimestamp,omitempty"`
+	Object         esObject          `json:"involvedObject"`
+	Source         map[string]string `json:"source,omitempty"`
+	EventName      string            `json:"eventName"`
+	EventUID       string            `json:"eventUID"`
+}
+
+type esObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// WriteESBulk writes an index action and document per event. Document IDs
+// combine the event UID and count, so re-indexing the same occurrence of an
+// event overwrites its document rather than duplicating it.
+func WriteESBulk(out io.Writer, summary *types.Summary, index string) error {
+	if index == "" {
+		index = DefaultESIndex
+	}
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+
+	for _, group := range summary.Groups {
+		for i, event := range group.Events {
+			action := map[string]map[string]string{
+				"index": {"_index": index, "_id": fmt.Sprintf("%s-%d", event.UID, event.Count)},
+			}
+			if err := enc.Encode(action); err != nil {
+				return err
+			}
+
+			doc := esDocument{
+				Timestamp: types.EventTime(event).UTC(),
+				Severity:  types.EventSeverity(event),
+				Group:     group.Key,
+				Cluster:   group.EventCluster(i),
+				Type:      event.Type,
+				Reason:    event.Reason,
+				Message:   event.Message,
+				Count:     event.Count,
+				Object: esObject{
+					Kind:      event.InvolvedObject.Kind,
+					Namespace: event.InvolvedObject.Namespace,
+					Name:      event.InvolvedObject.Name,
+					UID:       string(event.InvolvedObject.UID),
+				},
+				EventName: event.Name,
+				EventUID:  string(event.UID),
+			}
+			if doc.Timestamp.IsZero() {
+				doc.Timestamp = summary.Until.UTC()
+			}
+			if t := event.FirstTimestamp.Time; !t.IsZero() {
+				t = t.UTC()
+				doc.FirstTimestamp = &t
+			}
+			if t := event.LastTimestamp.Time; !t.IsZero() {
+				t = t.UTC()
+				doc.LastTimestamp = &t
+			}
+			if event.Source.Component != "" || event.Source.Host != "" {
+				doc.Source = map[string]string{"component": event.Source.Component, "host": event.Source.Host}
+			}
+			if err := enc.Encode(doc); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestESBulkGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "bulk.es.golden"},
+		{name: "bulk-index.es.golden", opts: Options{ESIndex: "events-prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, "es-bulk", tt.opts, testSummary())
+		})
+	}
+}
+
+func TestESBulkDocumentIDs(t *testing.T) {
+	summary := testSummary()
+	write := func() []string {
+		var out bytes.Buffer
+		if err := WriteESBulk(&out, summary, ""); err != nil {
+			t.Fatal(err)
+		}
+		// Actions and documents alternate, one JSON object per line
+		var ids []string
+		scanner := bufio.NewScanner(&out)
+		for i := 0; scanner.Scan(); i++ {
+			var line map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Fatalf("line %d: %v", i, err)
+			}
+			if i%2 == 1 {
+				if line["eventUID"] == nil {
+					t.Errorf("line %d: not an event document: %v", i, line)
+				}
+				continue
+			}
+			action, ok := line["index"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("line %d: not an index action: %v", i, line)
+			}
+			if action["_index"] != DefaultESIndex {
+				t.Errorf("got index %v, want %s", action["_index"], DefaultESIndex)
+			}
+			ids = append(ids, action["_id"].(string))
+		}
+		return ids
+	}
+
+	first := write()
+	if len(first) != 4 {
+		t.Fatalf("got %d actions, want 4", len(first))
+	}
+	// The same occurrence gets the same document, a new occurrence a new one
+	summary.Groups[0].Events[0].Count++
+	second := write()
+	if first[0] == second[0] || first[1] != second[1] {
+		t.Errorf("got IDs %v, then %v", first, second)
+	}
+}
@@ -1,26 +1,94 @@
 package output
 
 import (
+	"fmt"
 	"io"
-	
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/printers"
+
 	"github.com/nareshku/kubectl-event-summary/pkg/types"
 )
 
 // Formatter defines the interface for output formatters
 type Formatter interface {
-	Format(groups map[string]*types.GroupSummary, keys []string) error
+	Format(summary *types.Summary) error
+}
+
+// Options configures how formatters render the summary
+type Options struct {
+	// GroupBy is the comma-separated list of levels the groups were built from
+	GroupBy   string
+	Compact   bool
+	NoHeaders bool
+	// Width is the terminal width used to truncate messages, 0 disables truncation
+	Width int
+	// ESIndex is the index of the es-bulk output
+	ESIndex string
+}
+
+// formatNames lists the formats accepted by NewFormatter
+var formatNames = []string{
+	"wide", "json", "yaml", "table", "custom-columns=SPEC",
+	"go-template=TEMPLATE", "go-template-file=FILE", "jsonpath=TEMPLATE", "jsonpath-file=FILE",
+	"csv", "tsv", "markdown", "html", "prometheus", "openmetrics", "ndjson", "junit",
+	"github-annotations", "gitlab-codequality", "otlp-json",
+	"loki-push", "es-bulk", "cloudevents",
 }
 
 // NewFormatter creates a new formatter based on the format string
-func NewFormatter(format string, out io.Writer, compact bool) (Formatter, error) {
-	switch format {
+func NewFormatter(format string, out io.Writer, opts Options) (Formatter, error) {
+	name, arg, _ := strings.Cut(format, "=")
+	switch name {
 	case "wide":
-		return &WideFormatter{out: out, compact: compact}, nil
+		return &WideFormatter{out: out, compact: opts.Compact}, nil
+	case "table":
+		return &TableFormatter{out: out, opts: opts}, nil
+	case "custom-columns":
+		columns, err := parseCustomColumns(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &CustomColumnsFormatter{out: out, columns: columns, noHeaders: opts.NoHeaders}, nil
 	case "json":
-		return &JSONFormatter{out: out}, nil
+		return &PrinterFormatter{out: out, printer: &printers.JSONPrinter{}}, nil
 	case "yaml":
-		return &YAMLFormatter{out: out}, nil
+		return &PrinterFormatter{out: out, printer: &printers.YAMLPrinter{}}, nil
+	case "go-template", "go-template-file", "jsonpath", "jsonpath-file":
+		printer, err := newTemplatePrinter(name, arg)
+		if err != nil {
+			return nil, err
+		}
+		return &PrinterFormatter{out: out, printer: printer}, nil
+	case "csv":
+		return &CSVFormatter{out: out, comma: ',', opts: opts}, nil
+	case "tsv":
+		return &CSVFormatter{out: out, comma: '\t', opts: opts}, nil
+	case "markdown":
+		return &MarkdownFormatter{out: out, opts: opts}, nil
+	case "html":
+		return &HTMLFormatter{out: out, opts: opts}, nil
+	case "prometheus":
+		return &PrometheusFormatter{out: out}, nil
+	case "openmetrics":
+		return &PrometheusFormatter{out: out, openMetrics: true}, nil
+	case "ndjson":
+		return &NDJSONFormatter{out: out}, nil
+	case "junit":
+		return &JUnitFormatter{out: out, opts: opts}, nil
+	case "github-annotations":
+		return &GitHubAnnotationsFormatter{out: out}, nil
+	case "gitlab-codequality":
+		return &GitLabCodeQualityFormatter{out: out}, nil
+	case "otlp-json":
+		return &OTLPFormatter{out: out}, nil
+	case "loki-push":
+		return &LokiFormatter{out: out}, nil
+	case "es-bulk":
+		return &ESBulkFormatter{out: out, index: opts.ESIndex}, nil
+	case "cloudevents":
+		return &CloudEventsFormatter{out: out}, nil
 	default:
-		return nil, fmt.Errorf("unsupported format: %s", format)
+		return nil, fmt.Errorf("invalid format: %s, must be one of: %s", format, strings.Join(formatNames, ", "))
 	}
-} 
\ No newline at end of file
+}
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// windowEnd ends the window of the test summary
+var windowEnd = time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)
+
+func testEvent(namespace, kind, name, eventType, reason, message string, count int32, ago time.Duration) corev1.Event {
+	last := windowEnd.Add(-ago)
+	return corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "." + reason,
+			Namespace: namespace,
+			UID:       k8stypes.UID("uid-" + name + "-" + reason),
+		},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: namespace, Name: name},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: "kubelet"},
+		FirstTimestamp: metav1.NewTime(last.Add(-10 * time.Minute)),
+		LastTimestamp:  metav1.NewTime(last),
+		Count:          count,
+	}
+}
+
+// testGroup returns a group of the events, counted like the grouper does
+func testGroup(key string, events ...corev1.Event) types.Group {
+	group := &types.GroupSummary{Types: map[string]int{}, Reasons: map[string]int{}}
+	for _, event := range events {
+		group.Total++
+		group.Types[event.Type]++
+		group.Reasons[event.Reason]++
+		if event.Type == "Warning" {
+			group.Warnings++
+			if types.IsErrorReason(event.Reason) {
+				group.Errors++
+			}
+		}
+		group.AddEvent(event, "")
+	}
+	return types.Group{Key: key, GroupSummary: group}
+}
+
+// testSummary returns a summary grouped by namespace and reason whose
+// messages hold characters the formats must escape: quotes, commas, pipes,
+// markup, CDATA ends, percent signs and line breaks
+func testSummary() *types.Summary {
+	return &types.Summary{
+		TypeMeta: metav1.TypeMeta{Kind: types.SummaryKind, APIVersion: types.SummaryAPIVersion},
+		Totals:   types.Totals{Total: 10, Warnings: 4, Errors: 2},
+		Filtered: types.Totals{Total: 4, Warnings: 3, Errors: 2},
+		Since:    windowEnd.Add(-15 * time.Minute),
+		Until:    windowEnd,
+		GroupBy:  []string{"namespace", "reason"},
+		Groups: []types.Group{
+			testGroup("namespace=shop,reason=BackOff",
+				testEvent("shop", "Pod", "web-0", "Warning", "BackOff",
+					`Back-off restarting failed container "app", <retrying> & waiting`, 5, time.Minute),
+				testEvent("shop", "Pod", "web-1", "Warning", "BackOff",
+					"Back-off pulling image \"shop/web:1.2\"", 2, 3*time.Minute),
+			),
+			testGroup("namespace=shop,reason=Unhealthy",
+				testEvent("shop", "Pod", "web-1", "Warning", "Unhealthy",
+					"Readiness probe failed: | 503 | 100% ]]>\nbody: <h1>down</h1>", 3, 5*time.Minute),
+			),
+			testGroup("namespace=kube-system,reason=Pulled",
+				testEvent("kube-system", "Pod", "coredns-0", "Normal", "Pulled",
+					`Container image "coredns:1.11" already present on machine`, 1, 10*time.Minute),
+			),
+		},
+	}
+}
+
+// checkGolden compares got with testdata/name, or rewrites it with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file:\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+// formatGolden renders the summary in the format and compares it with the
+// golden file
+func formatGolden(t *testing.T, name, format string, opts Options, summary *types.Summary) {
+	t.Helper()
+	var out bytes.Buffer
+	formatter, err := NewFormatter(format, &out, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := formatter.Format(summary); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, name, out.Bytes())
+}
@@ -0,0 +1,173 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+//go:embed templates/report.html
+var reportTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(reportTemplate))
+
+const (
+	chartWidth   = 900
+	chartHeight  = 160
+	chartBuckets = 60
+)
+
+// HTMLFormatter renders the summary as a single self-contained HTML page
+// with sortable tables, a warnings timeline and a search box
+type HTMLFormatter struct {
+	out  io.Writer
+	opts Options
+}
+
+type htmlData struct {
+	Summary *types.Summary
+	Since   string
+	Until   string
+	GroupBy string
+	Compact bool
+	Chart   htmlChart
+	Groups  []htmlGroup
+}
+
+type htmlGroup struct {
+	Key        string
+	Total      int
+	Warnings   int
+	Errors     int
+	TopReasons string
+	Events     []htmlEvent
+}
+
+type htmlEvent struct {
+	LastSeen string
+	Type     string
+	Reason   string
+	Object   string
+	Count    int32
+	Message  string
+}
+
+type htmlChart struct {
+	Width  int
+	Height int
+	BaseY  int
+	LabelY int
+	Max    int
+	Bars   []htmlBar
+}
+
+type htmlBar struct {
+	X, Y, Width, Height int
+	Label               string
+	Count               int
+}
+
+func (f *HTMLFormatter) Format(summary *types.Summary) error {
+	data := htmlData{
+		Summary: summary,
+		Since:   summary.Since.UTC().Format(time.RFC3339),
+		Until:   summary.Until.UTC().Format(time.RFC3339),
+		GroupBy: strings.Join(summary.GroupBy, ", "),
+		Compact: f.opts.Compact,
+		Chart:   warningsChart(summary),
+	}
+
+	for _, group := range summary.Groups {
+		hg := htmlGroup{
+			Key:        group.Key,
+			Total:      group.Total,
+			Warnings:   group.Warnings,
+			Errors:     group.Errors,
+			TopReasons: formatCounts(topReasons(group.Reasons, 3)),
+		}
+		for _, event := range group.Events {
+			lastSeen := ""
+			if t := types.EventTime(event); !t.IsZero() {
+				lastSeen = t.UTC().Format(time.RFC3339)
+			}
+			hg.Events = append(hg.Events, htmlEvent{
+				LastSeen: lastSeen,
+				Type:     event.Type,
+				Reason:   event.Reason,
+				Object:   fmt.Sprintf("%s %s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Namespace, event.InvolvedObject.Name),
+				Count:    event.Count,
+				Message:  event.Message,
+			})
+		}
+		data.Groups = append(data.Groups, hg)
+	}
+
+	return htmlReport.Execute(f.out, data)
+}
+
+// warningsChart buckets the warning events of the window into bars
+func warningsChart(summary *types.Summary) htmlChart {
+	chart := htmlChart{
+		Width:  chartWidth,
+		Height: chartHeight,
+		BaseY:  chartHeight - 20,
+		LabelY: chartHeight - 5,
+	}
+
+	counts := make([]int, chartBuckets)
+	window := summary.Until.Sub(summary.Since)
+	if window <= 0 {
+		return chart
+	}
+	bucketSize := window / chartBuckets
+	// Windows shorter than chartBuckets nanoseconds would divide by zero
+	if bucketSize < 1 {
+		bucketSize = 1
+	}
+	for _, group := range summary.Groups {
+		for _, event := range group.Events {
+			if event.Type != "Warning" {
+				continue
+			}
+			t := types.EventTime(event)
+			i := int(t.Sub(summary.Since) / bucketSize)
+			if i < 0 || t.IsZero() {
+				continue
+			}
+			if i >= chartBuckets {
+				i = chartBuckets - 1
+			}
+			counts[i]++
+		}
+	}
+
+	for _, count := range counts {
+		if count > chart.Max {
+			chart.Max = count
+		}
+	}
+
+	barWidth := chartWidth / chartBuckets
+	maxHeight := chart.BaseY - 15
+	for i, count := range counts {
+		height := 0
+		if chart.Max > 0 {
+			height = count * maxHeight / chart.Max
+		}
+		start := summary.Since.Add(time.Duration(i) * bucketSize)
+		chart.Bars = append(chart.Bars, htmlBar{
+			X:      i * barWidth,
+			Y:      chart.BaseY - height,
+			Width:  barWidth - 1,
+			Height: height,
+			Label:  start.UTC().Format(time.RFC3339),
+			Count:  count,
+		})
+	}
+	return chart
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTMLGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "report.html.golden", opts: Options{GroupBy: "namespace,reason"}},
+		{name: "report-compact.html.golden", opts: Options{GroupBy: "namespace,reason", Compact: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, "html", tt.opts, testSummary())
+		})
+	}
+}
+
+func TestHTMLEscaping(t *testing.T) {
+	summary := multiClusterSummary()
+	summary.Groups[0].Key = `namespace=<script>alert("x")</script>,reason=BackOff`
+
+	var out bytes.Buffer
+	f := &HTMLFormatter{out: &out, opts: Options{GroupBy: "namespace,reason"}}
+	if err := f.Format(summary); err != nil {
+		t.Fatal(err)
+	}
+	report := out.String()
+
+	for _, raw := range []string{`<script>alert`, "<h1>down", "<retrying>"} {
+		if strings.Contains(report, raw) {
+			t.Errorf("report holds unescaped %s", raw)
+		}
+	}
+	for _, escaped := range []string{
+		"&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;",
+		"&lt;h1&gt;down&lt;/h1&gt;",
+		"&#34;app&#34;, &lt;retrying&gt; &amp; waiting",
+	} {
+		if !strings.Contains(report, escaped) {
+			t.Errorf("report lacks %s", escaped)
+		}
+	}
+}
+
+func TestWarningsChart(t *testing.T) {
+	summary := testSummary()
+	chart := warningsChart(summary)
+	if len(chart.Bars) != chartBuckets {
+		t.Fatalf("got %d bars, want %d", len(chart.Bars), chartBuckets)
+	}
+	// Three warnings at 10:14, 10:12 and 10:10 of a 15 minute window
+	warnings := 0
+	for _, bar := range chart.Bars {
+		warnings += bar.Count
+	}
+	if warnings != 3 || chart.Max != 1 {
+		t.Errorf("got %d warnings with a maximum of %d, want 3 and 1", warnings, chart.Max)
+	}
+	if bar := chart.Bars[56]; bar.Count != 1 || bar.Label != "2024-05-01T10:14:00Z" {
+		t.Errorf("got bar %+v for 10:14", bar)
+	}
+
+	// Windows too short to split, or empty, do not divide by zero
+	for _, window := range []time.Duration{time.Nanosecond, 0} {
+		summary.Since = summary.Until.Add(-window)
+		chart := warningsChart(summary)
+		if window > 0 && len(chart.Bars) != chartBuckets {
+			t.Errorf("%s: got %d bars", window, len(chart.Bars))
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// junitTimestamp is the timestamp layout of the JUnit schema
+const junitTimestamp = "2006-01-02T15:04:05"
+
+// JUnitFormatter renders the summary as a JUnit XML report. Each group is a
+// testsuite and each event a testcase that fails for warning and error
+// events; --fail-on rules become testcases of a "thresholds" testsuite.
+type JUnitFormatter struct {
+	out  io.Writer
+	opts Options
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (f *JUnitFormatter) Format(summary *types.Summary) error {
+	report := junitTestSuites{Name: "kubectl-event-summary"}
+	timestamp := summary.Until.UTC().Format(junitTimestamp)
+
+	for _, group := range summary.Groups {
+		suite := junitTestSuite{Name: group.Key, Timestamp: timestamp}
+		if f.opts.Compact {
+			// One testcase per group, failing if it has warnings
+			tc := junitTestCase{Name: group.Key, ClassName: group.Key}
+			if group.Warnings > 0 {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%d warnings, %d errors", group.Warnings, group.Errors),
+					Type:    "warning",
+					Text:    formatCounts(topReasons(group.Reasons, 3)),
+				}
+				if group.Errors > 0 {
+					tc.Failure.Type = "error"
+				}
+			}
+			suite.add(tc)
+		} else {
+			for _, event := range group.Events {
+				tc := junitTestCase{
+					Name: fmt.Sprintf("%s %s/%s: %s", event.InvolvedObject.Kind,
+						event.InvolvedObject.Namespace, event.InvolvedObject.Name, event.Reason),
+					ClassName: group.Key,
+				}
+				if severity := types.EventSeverity(event); severity != types.SeverityNormal {
+					tc.Failure = &junitFailure{
+						Message: event.Reason,
+						Type:    string(severity),
+						Text:    event.Message,
+					}
+				}
+				suite.add(tc)
+			}
+		}
+		report.add(suite)
+	}
+
+	if len(summary.Thresholds) > 0 {
+		suite := junitTestSuite{Name: "thresholds", Timestamp: timestamp}
+		for _, result := range summary.Thresholds {
+			tc := junitTestCase{Name: result.Rule, ClassName: "thresholds"}
+			if result.Breached {
+				tc.Failure = &junitFailure{
+					Message: "threshold breached",
+					Type:    "threshold",
+					Text:    result.String(),
+				}
+			}
+			suite.add(tc)
+		}
+		report.add(suite)
+	}
+
+	if _, err := io.WriteString(f.out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(f.out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(f.out, "\n")
+	return err
+}
+
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+	s.Cases = append(s.Cases, tc)
+}
+
+func (s *junitTestSuites) add(suite junitTestSuite) {
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+	s.Suites = append(s.Suites, suite)
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// thresholdSummary returns the test summary with a breached and a passed
+// --fail-on rule
+func thresholdSummary() *types.Summary {
+	summary := testSummary()
+	summary.Thresholds = []types.ThresholdResult{
+		{Rule: "warnings>2", Field: "warnings", Actual: 3, Breached: true},
+		{Rule: "reason=BackOff>5", Field: "count", Actual: 2},
+	}
+	return summary
+}
+
+func TestJUnitGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "report.junit.golden"},
+		{name: "report-compact.junit.golden", opts: Options{Compact: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, "junit", tt.opts, thresholdSummary())
+		})
+	}
+}
+
+func TestJUnitEscaping(t *testing.T) {
+	summary := thresholdSummary()
+	// Terminal escapes are not valid in XML 1.0 at all
+	summary.Groups[2].Events[0].Message = "pulled \x1b[1mcoredns\x1b[0m <ok> & \"done\""
+
+	var out bytes.Buffer
+	f := &JUnitFormatter{out: &out}
+	if err := f.Format(summary); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out.String())
+	}
+	if report.Tests != 6 || report.Failures != 4 {
+		t.Errorf("got %d tests and %d failures, want 6 and 4", report.Tests, report.Failures)
+	}
+	unhealthy := report.Suites[1].Cases[0]
+	if want := "Readiness probe failed: | 503 | 100% ]]>\nbody: <h1>down</h1>"; unhealthy.Failure == nil || unhealthy.Failure.Text != want {
+		t.Errorf("got failure %+v, want text %q", unhealthy.Failure, want)
+	}
+	backOff := report.Suites[0].Cases[0]
+	if want := `Pod shop/web-0: BackOff`; backOff.Name != want || backOff.Failure.Type != "error" {
+		t.Errorf("got testcase %q failing with %q, want %q failing with error", backOff.Name, backOff.Failure.Type, want)
+	}
+	if pulled := report.Suites[2].Cases[0]; pulled.Failure != nil {
+		t.Errorf("normal event %q failed", pulled.Name)
+	}
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// LokiJob is the job label of the streams pushed to Loki
+const LokiJob = "kubectl-event-summary"
+
+// LokiFormatter writes the events of the summary as a Loki push API request,
+// with a stream per group labeled with the group key dimensions
+type LokiFormatter struct {
+	out io.Writer
+}
+
+func (f *LokiFormatter) Format(summary *types.Summary) error {
+	data, err := LokiPush(summary)
+	if err != nil {
+		return err
+	}
+	_, err = f.out.Write(append(data, '\n'))
+	return err
+}
+
+// lokiLine is the JSON log line of an event
+type lokiLine struct {
+	Severity  types.Severity `json:"severity"`
+	Type      string         `json:"type"`
+	Reason    string         `json:"reason"`
+	Kind      string         `json:"kind"`
+	Namespace string         `json:"namespace,omitempty"`
+	Name      string         `json:"name"`
+	Count     int32          `json:"count"`
+	Message   string         `json:"message"`
+}
+
+// LokiPush encodes the events of the summary as a Loki push API request.
+// Events without a timestamp are logged at the end of the window.
+func LokiPush(summary *types.Summary) ([]byte, error) {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	request := struct {
+		Streams []stream `json:"streams"`
+	}{Streams: []stream{}}
+
+	for _, group := range summary.Groups {
+		if len(group.Events) == 0 {
+			continue
+		}
+		labels := map[string]string{"job": LokiJob}
+		if len(summary.GroupBy) > 0 {
+			for i, value := range SplitGroupKey(group.Key, summary.GroupBy) {
+				labels[summary.GroupBy[i]] = value
+			}
+		} else {
+			labels["group"] = group.Key
+		}
+
+		events := append(group.Events[:0:0], group.Events...)
+		sort.SliceStable(events, func(i, j int) bool { return types.EventTime(events[i]).Before(types.EventTime(events[j])) })
+
+		s := stream{Stream: labels}
+		for _, event := range events {
+			t := types.EventTime(event)
+			if t.IsZero() {
+				t = summary.Until
+			}
+			line, err := json.Marshal(lokiLine{
+				Severity:  types.EventSeverity(event),
+				Type:      event.Type,
+				Reason:    event.Reason,
+				Kind:      event.InvolvedObject.Kind,
+				Namespace: event.InvolvedObject.Namespace,
+				Name:      event.InvolvedObject.Name,
+				Count:     event.Count,
+				Message:   event.Message,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode log line: %v", err)
+			}
+			s.Values = append(s.Values, [2]string{strconv.FormatInt(t.UnixNano(), 10), string(line)})
+		}
+		request.Streams = append(request.Streams, s)
+	}
+	return json.Marshal(request)
+}
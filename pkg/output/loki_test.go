@@ -0,0 +1,101 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// lokiRequest is a decoded Loki push request
+type lokiRequest struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+func TestLokiGolden(t *testing.T) {
+	formatGolden(t, "push.loki.golden", "loki-push", Options{}, testSummary())
+}
+
+func TestLokiStreams(t *testing.T) {
+	data, err := LokiPush(testSummary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request lokiRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatal(err)
+	}
+	if len(request.Streams) != 3 {
+		t.Fatalf("got %d streams, want one per group", len(request.Streams))
+	}
+
+	backOff := request.Streams[0]
+	if want := map[string]string{"job": LokiJob, "namespace": "shop", "reason": "BackOff"}; !reflect.DeepEqual(backOff.Stream, want) {
+		t.Errorf("got labels %v, want %v", backOff.Stream, want)
+	}
+	// Entries are in time order, stamped in nanoseconds
+	want := []string{
+		fmt.Sprint(windowEnd.Add(-3 * time.Minute).UnixNano()),
+		fmt.Sprint(windowEnd.Add(-time.Minute).UnixNano()),
+	}
+	if got := []string{backOff.Values[0][0], backOff.Values[1][0]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got timestamps %v, want %v", got, want)
+	}
+	var line lokiLine
+	if err := json.Unmarshal([]byte(backOff.Values[1][1]), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line.Name != "web-0" || line.Severity != "error" || line.Message != `Back-off restarting failed container "app", <retrying> & waiting` {
+		t.Errorf("got line %+v", line)
+	}
+}
+
+func TestLokiLabelCardinality(t *testing.T) {
+	// Many pods, each with its own messages, stay in the streams of their
+	// group: objects and messages go in the lines, not the labels
+	var events []corev1.Event
+	for i := 0; i < 50; i++ {
+		pod := fmt.Sprintf("web-%d", i)
+		events = append(events, testEvent("shop", "Pod", pod, "Warning", "BackOff", "Back-off restarting "+pod, 1, time.Duration(i)*time.Second))
+	}
+	summary := testSummary()
+	summary.Groups = []types.Group{testGroup("namespace=shop,reason=BackOff", events...)}
+
+	data, err := LokiPush(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request lokiRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatal(err)
+	}
+	if len(request.Streams) != 1 || len(request.Streams[0].Values) != 50 {
+		t.Fatalf("got %d streams, want 1 with 50 entries", len(request.Streams))
+	}
+	if got := len(request.Streams[0].Stream); got != 3 {
+		t.Errorf("got labels %v, want job, namespace and reason", request.Streams[0].Stream)
+	}
+
+	// Without --group-by, the only label besides the job is the group
+	summary.GroupBy = nil
+	summary.Groups[0].Key = "all events"
+	data, err = LokiPush(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request = lokiRequest{}
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"job": LokiJob, "group": "all events"}; !reflect.DeepEqual(request.Streams[0].Stream, want) {
+		t.Errorf("got labels %v, want %v", request.Streams[0].Stream, want)
+	}
+}
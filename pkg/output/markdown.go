@@ -0,0 +1,125 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// MarkdownFormatter renders the summary as a Markdown report that can be
+// pasted into issues and postmortem documents
+type MarkdownFormatter struct {
+	out  io.Writer
+	opts Options
+}
+
+func (f *MarkdownFormatter) Format(summary *types.Summary) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## Event Summary\n\n")
+	fmt.Fprintf(&b, "- **Window:** %s → %s\n",
+		summary.Since.UTC().Format(time.RFC3339),
+		summary.Until.UTC().Format(time.RFC3339))
+	if len(summary.GroupBy) > 0 {
+		fmt.Fprintf(&b, "- **Grouped by:** %s\n", strings.Join(summary.GroupBy, ", "))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("| Scope | Total | Warnings | Errors |\n")
+	b.WriteString("|---|---:|---:|---:|\n")
+	writeTotalsRow(&b, "All events", summary.Totals)
+	var unreachable []string
+	for _, cluster := range summary.Clusters {
+		if cluster.Error != "" {
+			unreachable = append(unreachable, fmt.Sprintf("`%s` (%s)", cluster.Name, markdownEscape(cluster.Error)))
+			continue
+		}
+		writeTotalsRow(&b, "Cluster `"+cluster.Name+"`", cluster.Totals)
+	}
+	writeTotalsRow(&b, "Filtered", summary.Filtered)
+	if len(unreachable) > 0 {
+		fmt.Fprintf(&b, "\n**Unreachable clusters:** %s\n", strings.Join(unreachable, ", "))
+	}
+
+	allReasons := make(map[string]int)
+	for _, group := range summary.Groups {
+		for reason, count := range group.Reasons {
+			allReasons[reason] += count
+		}
+	}
+	if len(allReasons) > 0 {
+		b.WriteString("\n### Top Reasons\n\n")
+		b.WriteString("| Reason | Events |\n")
+		b.WriteString("|---|---:|\n")
+		for _, rc := range topReasons(allReasons, 10) {
+			fmt.Fprintf(&b, "| %s | %d |\n", markdownEscape(rc.Reason), rc.Count)
+		}
+	}
+
+	b.WriteString("\n### Groups\n\n")
+	b.WriteString("| Group | Total | Warnings | Errors | Top Reasons |\n")
+	b.WriteString("|---|---:|---:|---:|---|\n")
+	for _, group := range summary.Groups {
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %s |\n",
+			markdownEscape(group.Key),
+			group.Total,
+			group.Warnings,
+			group.Errors,
+			markdownEscape(formatCounts(topReasons(group.Reasons, 3))))
+	}
+
+	if !f.opts.Compact {
+		for _, group := range summary.Groups {
+			if len(group.Events) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "\n<details>\n<summary>%s (%d events)</summary>\n\n", htmlEscaper.Replace(group.Key), len(group.Events))
+			b.WriteString("| Last Seen | Type | Reason | Object | Count | Message |\n")
+			b.WriteString("|---|---|---|---|---:|---|\n")
+			for _, event := range group.Events {
+				lastSeen := ""
+				if t := types.EventTime(event); !t.IsZero() {
+					lastSeen = t.UTC().Format(time.RFC3339)
+				}
+				fmt.Fprintf(&b, "| %s | %s | %s | %s | %d | %s |\n",
+					lastSeen,
+					event.Type,
+					markdownEscape(event.Reason),
+					markdownEscape(fmt.Sprintf("%s %s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Namespace, event.InvolvedObject.Name)),
+					event.Count,
+					markdownEscape(event.Message))
+			}
+			b.WriteString("\n</details>\n")
+		}
+	}
+
+	_, err := io.WriteString(f.out, b.String())
+	return err
+}
+
+func writeTotalsRow(b *strings.Builder, scope string, totals types.Totals) {
+	fmt.Fprintf(b, "| %s | %d | %d | %d |\n", scope, totals.Total, totals.Warnings, totals.Errors)
+}
+
+// markdownEscaper keeps cell content from breaking table rows
+var markdownEscaper = strings.NewReplacer(
+	"|", "\\|",
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+// htmlEscaper escapes text placed inside HTML tags such as <summary>
+var htmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// multiClusterSummary returns the test summary fetched from two clusters,
+// one of them unreachable
+func multiClusterSummary() *types.Summary {
+	summary := testSummary()
+	summary.Clusters = []types.ClusterTotals{
+		{Name: "prod", Totals: summary.Totals},
+		{Name: "staging", Error: `timed out | "dial tcp 10.0.0.1:443"`},
+	}
+	return summary
+}
+
+func TestMarkdownGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		summary *types.Summary
+	}{
+		{name: "report.md.golden", opts: Options{GroupBy: "namespace,reason"}, summary: testSummary()},
+		{name: "report-compact.md.golden", opts: Options{GroupBy: "namespace,reason", Compact: true}, summary: testSummary()},
+		{name: "report-clusters.md.golden", opts: Options{GroupBy: "namespace,reason", Compact: true}, summary: multiClusterSummary()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, "markdown", tt.opts, tt.summary)
+		})
+	}
+}
+
+func TestMarkdownEscaping(t *testing.T) {
+	var out bytes.Buffer
+	f := &MarkdownFormatter{out: &out, opts: Options{GroupBy: "namespace,reason"}}
+	if err := f.Format(multiClusterSummary()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every row of a table keeps the columns of its header, whatever the
+	// messages hold
+	unescapedPipe := regexp.MustCompile(`(^|[^\\])\|`)
+	columns := 0
+	for _, line := range strings.Split(out.String(), "\n") {
+		if !strings.HasPrefix(line, "|") {
+			columns = 0
+			continue
+		}
+		cells := len(unescapedPipe.FindAllString(line, -1)) - 1
+		if columns == 0 {
+			columns = cells
+		} else if cells != columns {
+			t.Errorf("row has %d cells, want %d: %s", cells, columns, line)
+		}
+	}
+
+	// Markup in messages is shown, not rendered
+	for _, raw := range []string{"<h1>", "<retrying>"} {
+		if strings.Contains(out.String(), raw) {
+			t.Errorf("report holds unescaped %s", raw)
+		}
+	}
+	if !strings.Contains(out.String(), `Readiness probe failed: \| 503 \| 100% ]]&gt;<br>body: &lt;h1&gt;down&lt;/h1&gt;`) {
+		t.Errorf("message not escaped:\n%s", out.String())
+	}
+}
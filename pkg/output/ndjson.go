@@ -0,0 +1,67 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// EventStreamer is implemented by formatters that write each event as soon
+// as it passes the filters. Streamed events are not kept in the summary
+// passed to Format afterwards.
+type EventStreamer interface {
+	StreamEvent(event corev1.Event, cluster, groupKey string) error
+}
+
+// NDJSONFormatter writes one JSON object per line: a record for each event
+// followed by a final summary record
+type NDJSONFormatter struct {
+	out io.Writer
+}
+
+// ndjsonEvent is the record written for each event
+type ndjsonEvent struct {
+	Record    string         `json:"record"`
+	Timestamp string         `json:"timestamp,omitempty"`
+	Severity  types.Severity `json:"severity"`
+	Cluster   string         `json:"cluster,omitempty"`
+	Group     string         `json:"group"`
+	Event     corev1.Event   `json:"event"`
+}
+
+// ndjsonSummary is the final record, the summary document without events
+type ndjsonSummary struct {
+	Record string `json:"record"`
+	*types.Summary
+}
+
+func (f *NDJSONFormatter) StreamEvent(event corev1.Event, cluster, groupKey string) error {
+	record := ndjsonEvent{
+		Record:   "event",
+		Severity: types.EventSeverity(event),
+		Cluster:  cluster,
+		Group:    groupKey,
+		Event:    event,
+	}
+	if t := types.EventTime(event); !t.IsZero() {
+		record.Timestamp = t.UTC().Format(time.RFC3339)
+	}
+	return json.NewEncoder(f.out).Encode(record)
+}
+
+func (f *NDJSONFormatter) Format(summary *types.Summary) error {
+	// Events still held by the summary were not streamed
+	for _, group := range summary.Groups {
+		for i, event := range group.Events {
+			if err := f.StreamEvent(event, group.EventCluster(i), group.Key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return json.NewEncoder(f.out).Encode(ndjsonSummary{Record: "summary", Summary: summary.WithoutEvents()})
+}
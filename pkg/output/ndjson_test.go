@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNDJSONGolden(t *testing.T) {
+	formatGolden(t, "events.ndjson.golden", "ndjson", Options{}, testSummary())
+}
+
+func TestNDJSONStreaming(t *testing.T) {
+	summary := testSummary()
+	var out bytes.Buffer
+	f := &NDJSONFormatter{out: &out}
+
+	// Stream the events of the first group, as the filters pass them, and
+	// leave them out of the summary
+	first := summary.Groups[0]
+	for _, event := range first.Events {
+		if err := f.StreamEvent(event, "prod", first.Key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	summary.Groups[0].GroupSummary = first.FilterEvents(func(corev1.Event) bool { return false })
+	if err := f.Format(summary); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each line is a JSON object; every event is written once, before the
+	// summary, which holds no events
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid line %s: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 5 {
+		t.Fatalf("got %d records, want 4 events and the summary", len(records))
+	}
+	for i, record := range records[:4] {
+		if record["record"] != "event" {
+			t.Errorf("record %d: got %v, want an event", i, record["record"])
+		}
+	}
+	if records[0]["cluster"] != "prod" || records[2]["cluster"] != nil {
+		t.Errorf("got clusters %v and %v, want prod and none", records[0]["cluster"], records[2]["cluster"])
+	}
+	last := records[4]
+	if last["record"] != "summary" {
+		t.Fatalf("got last record %v, want the summary", last["record"])
+	}
+	for _, group := range last["groups"].([]interface{}) {
+		if events := group.(map[string]interface{})["events"]; events != nil {
+			t.Errorf("summary record holds events %v", events)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// OTLPScope is the instrumentation scope of the exported log records
+const OTLPScope = "github.com/nareshku/kubectl-event-summary"
+
+// OTLP severity numbers
+const (
+	otlpSeverityInfo  = 9
+	otlpSeverityWarn  = 13
+	otlpSeverityError = 17
+)
+
+// otlpResourceNames maps involved object kinds to the semantic convention
+// attributes naming them
+var otlpResourceNames = map[string]string{
+	"Pod":         "k8s.pod.name",
+	"Node":        "k8s.node.name",
+	"Deployment":  "k8s.deployment.name",
+	"ReplicaSet":  "k8s.replicaset.name",
+	"StatefulSet": "k8s.statefulset.name",
+	"DaemonSet":   "k8s.daemonset.name",
+	"Job":         "k8s.job.name",
+	"CronJob":     "k8s.cronjob.name",
+}
+
+// OTLPFormatter writes the events of the summary as an OTLP/JSON
+// ExportLogsServiceRequest
+type OTLPFormatter struct {
+	out io.Writer
+}
+
+func (f *OTLPFormatter) Format(summary *types.Summary) error {
+	data, err := OTLPLogsJSON(summary, time.Now())
+	if err != nil {
+		return err
+	}
+	_, err = f.out.Write(append(data, '\n'))
+	return err
+}
+
+// otlpAttribute is a key with a string or int value
+type otlpAttribute struct {
+	Key      string
+	Value    string
+	IntValue int64
+	IsInt    bool
+}
+
+type otlpRecord struct {
+	Time       time.Time
+	Severity   int
+	Text       string
+	Body       string
+	Attributes []otlpAttribute
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute
+	Records    []otlpRecord
+}
+
+// otlpResources converts the events of the summary into log records, grouped
+// by the resource (cluster, namespace, object and node) they describe
+func otlpResources(summary *types.Summary) []*otlpResource {
+	var resources []*otlpResource
+	byKey := make(map[string]*otlpResource)
+	for _, group := range summary.Groups {
+		for i, event := range group.Events {
+			attrs := otlpResourceAttributes(event, group.EventCluster(i))
+			var parts []string
+			for _, attr := range attrs {
+				parts = append(parts, attr.Key+"="+attr.Value)
+			}
+			key := strings.Join(parts, "\x00")
+			resource := byKey[key]
+			if resource == nil {
+				resource = &otlpResource{Attributes: attrs}
+				byKey[key] = resource
+				resources = append(resources, resource)
+			}
+			resource.Records = append(resource.Records, otlpLogRecord(event))
+		}
+	}
+	return resources
+}
+
+func otlpResourceAttributes(event corev1.Event, cluster string) []otlpAttribute {
+	obj := event.InvolvedObject
+	var attrs []otlpAttribute
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, otlpAttribute{Key: key, Value: value})
+		}
+	}
+
+	add("k8s.cluster.name", cluster)
+	add("k8s.namespace.name", obj.Namespace)
+	if name, ok := otlpResourceNames[obj.Kind]; ok {
+		add(name, obj.Name)
+	}
+	if obj.Kind == "Pod" {
+		add("k8s.pod.uid", string(obj.UID))
+	}
+	// Kubelet events are reported from the node the object runs on
+	if obj.Kind != "Node" {
+		add("k8s.node.name", event.Source.Host)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs
+}
+
+func otlpLogRecord(event corev1.Event) otlpRecord {
+	record := otlpRecord{
+		Time: types.EventTime(event),
+		Body: event.Message,
+	}
+	switch types.EventSeverity(event) {
+	case types.SeverityError:
+		record.Severity, record.Text = otlpSeverityError, "ERROR"
+	case types.SeverityWarning:
+		record.Severity, record.Text = otlpSeverityWarn, "WARN"
+	default:
+		record.Severity, record.Text = otlpSeverityInfo, "INFO"
+	}
+
+	count := event.Count
+	if count < 1 {
+		count = 1
+	}
+	record.Attributes = []otlpAttribute{
+		{Key: "k8s.event.reason", Value: event.Reason},
+		{Key: "k8s.event.type", Value: event.Type},
+		{Key: "k8s.event.name", Value: event.Name},
+		{Key: "k8s.event.uid", Value: string(event.UID)},
+		{Key: "k8s.event.count", IntValue: int64(count), IsInt: true},
+		{Key: "k8s.object.kind", Value: event.InvolvedObject.Kind},
+		{Key: "k8s.object.name", Value: event.InvolvedObject.Name},
+	}
+	if event.Source.Component != "" {
+		record.Attributes = append(record.Attributes, otlpAttribute{Key: "k8s.event.source.component", Value: event.Source.Component})
+	}
+	return record
+}
+
+// OTLPLogsJSON encodes the events of the summary as an OTLP/JSON
+// ExportLogsServiceRequest, observed at now
+func OTLPLogsJSON(summary *types.Summary, now time.Time) ([]byte, error) {
+	type anyValue struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		IntValue    string  `json:"intValue,omitempty"`
+	}
+	type keyValue struct {
+		Key   string   `json:"key"`
+		Value anyValue `json:"value"`
+	}
+	attributes := func(attrs []otlpAttribute) []keyValue {
+		kvs := make([]keyValue, 0, len(attrs))
+		for _, attr := range attrs {
+			kv := keyValue{Key: attr.Key}
+			if attr.IsInt {
+				kv.Value.IntValue = strconv.FormatInt(attr.IntValue, 10)
+			} else {
+				value := attr.Value
+				kv.Value.StringValue = &value
+			}
+			kvs = append(kvs, kv)
+		}
+		return kvs
+	}
+
+	type logRecord struct {
+		TimeUnixNano         string     `json:"timeUnixNano,omitempty"`
+		ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+		SeverityNumber       int        `json:"severityNumber"`
+		SeverityText         string     `json:"severityText"`
+		Body                 anyValue   `json:"body"`
+		Attributes           []keyValue `json:"attributes"`
+	}
+	type scope struct {
+		Name string `json:"name"`
+	}
+	type scopeLogs struct {
+		Scope      scope       `json:"scope"`
+		LogRecords []logRecord `json:"logRecords"`
+	}
+	type resource struct {
+		Attributes []keyValue `json:"attributes"`
+	}
+	type resourceLogs struct {
+		Resource  resource    `json:"resource"`
+		ScopeLogs []scopeLogs `json:"scopeLogs"`
+	}
+	request := struct {
+		ResourceLogs []resourceLogs `json:"resourceLogs"`
+	}{ResourceLogs: []resourceLogs{}}
+
+	observed := strconv.FormatInt(now.UnixNano(), 10)
+	for _, r := range otlpResources(summary) {
+		sl := scopeLogs{Scope: scope{Name: OTLPScope}}
+		for _, record := range r.Records {
+			body := record.Body
+			lr := logRecord{
+				ObservedTimeUnixNano: observed,
+				SeverityNumber:       record.Severity,
+				SeverityText:         record.Text,
+				Body:                 anyValue{StringValue: &body},
+				Attributes:           attributes(record.Attributes),
+			}
+			if !record.Time.IsZero() {
+				lr.TimeUnixNano = strconv.FormatInt(record.Time.UnixNano(), 10)
+			}
+			sl.LogRecords = append(sl.LogRecords, lr)
+		}
+		request.ResourceLogs = append(request.ResourceLogs, resourceLogs{
+			Resource:  resource{Attributes: attributes(r.Attributes)},
+			ScopeLogs: []scopeLogs{sl},
+		})
+	}
+	return json.Marshal(request)
+}
+
+// OTLPLogsProtobuf encodes the events of the summary as a protobuf
+// ExportLogsServiceRequest, observed at now
+func OTLPLogsProtobuf(summary *types.Summary, now time.Time) []byte {
+	var request []byte
+	for _, r := range otlpResources(summary) {
+		var resource []byte
+		for _, attr := range r.Attributes {
+			resource = appendMessage(resource, 1, appendKeyValue(nil, attr))
+		}
+
+		var scopeLogs []byte
+		scopeLogs = appendMessage(scopeLogs, 1, protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), OTLPScope))
+		for _, record := range r.Records {
+			var lr []byte
+			if !record.Time.IsZero() {
+				lr = protowire.AppendTag(lr, 1, protowire.Fixed64Type)
+				lr = protowire.AppendFixed64(lr, uint64(record.Time.UnixNano()))
+			}
+			lr = protowire.AppendTag(lr, 2, protowire.VarintType)
+			lr = protowire.AppendVarint(lr, uint64(record.Severity))
+			lr = protowire.AppendTag(lr, 3, protowire.BytesType)
+			lr = protowire.AppendString(lr, record.Text)
+			lr = appendMessage(lr, 5, appendStringValue(nil, record.Body))
+			for _, attr := range record.Attributes {
+				lr = appendMessage(lr, 6, appendKeyValue(nil, attr))
+			}
+			lr = protowire.AppendTag(lr, 11, protowire.Fixed64Type)
+			lr = protowire.AppendFixed64(lr, uint64(now.UnixNano()))
+			scopeLogs = appendMessage(scopeLogs, 2, lr)
+		}
+
+		var resourceLogs []byte
+		resourceLogs = appendMessage(resourceLogs, 1, resource)
+		resourceLogs = appendMessage(resourceLogs, 2, scopeLogs)
+		request = appendMessage(request, 1, resourceLogs)
+	}
+	return request
+}
+
+// appendMessage appends an embedded message field
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// appendKeyValue appends the fields of a KeyValue message
+func appendKeyValue(b []byte, attr otlpAttribute) []byte {
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, attr.Key)
+	var value []byte
+	if attr.IsInt {
+		value = protowire.AppendTag(nil, 3, protowire.VarintType)
+		value = protowire.AppendVarint(value, uint64(attr.IntValue))
+	} else {
+		value = appendStringValue(nil, attr.Value)
+	}
+	return appendMessage(b, 2, value)
+}
+
+// appendStringValue appends the fields of an AnyValue holding a string
+func appendStringValue(b []byte, s string) []byte {
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
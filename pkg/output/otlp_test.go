@@ -0,0 +1,169 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// attributeMap returns the attributes as strings, ints formatted in decimal
+func attributeMap(attrs []*commonpb.KeyValue) map[string]interface{} {
+	values := make(map[string]interface{}, len(attrs))
+	for _, attr := range attrs {
+		switch value := attr.Value.Value.(type) {
+		case *commonpb.AnyValue_StringValue:
+			values[attr.Key] = value.StringValue
+		case *commonpb.AnyValue_IntValue:
+			values[attr.Key] = value.IntValue
+		default:
+			values[attr.Key] = value
+		}
+	}
+	return values
+}
+
+// checkOTLPRequest checks the decoded export request of the test summary,
+// observed at now
+func checkOTLPRequest(t *testing.T, request *collogspb.ExportLogsServiceRequest, now time.Time) {
+	t.Helper()
+	// web-0, web-1 and coredns-0 are separate resources; both web-1 events
+	// share one
+	if len(request.ResourceLogs) != 3 {
+		t.Fatalf("got %d resources, want 3", len(request.ResourceLogs))
+	}
+
+	web1 := request.ResourceLogs[1]
+	if got, want := attributeMap(web1.Resource.Attributes), map[string]interface{}{
+		"k8s.namespace.name": "shop",
+		"k8s.pod.name":       "web-1",
+	}; !equalValues(got, want) {
+		t.Errorf("got resource attributes %v, want %v", got, want)
+	}
+	if len(web1.ScopeLogs) != 1 || web1.ScopeLogs[0].Scope.Name != OTLPScope {
+		t.Fatalf("got scope logs %v", web1.ScopeLogs)
+	}
+	records := web1.ScopeLogs[0].LogRecords
+	if len(records) != 2 {
+		t.Fatalf("got %d records for web-1, want 2", len(records))
+	}
+
+	tests := []struct {
+		record   *logspb.LogRecord
+		severity logspb.SeverityNumber
+		text     string
+		body     string
+		time     time.Time
+		attrs    map[string]interface{}
+	}{
+		{
+			record:   records[0],
+			severity: logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+			text:     "ERROR",
+			body:     `Back-off pulling image "shop/web:1.2"`,
+			time:     windowEnd.Add(-3 * time.Minute),
+			attrs: map[string]interface{}{
+				"k8s.event.reason":           "BackOff",
+				"k8s.event.type":             "Warning",
+				"k8s.event.name":             "web-1.BackOff",
+				"k8s.event.uid":              "uid-web-1-BackOff",
+				"k8s.event.count":            int64(2),
+				"k8s.object.kind":            "Pod",
+				"k8s.object.name":            "web-1",
+				"k8s.event.source.component": "kubelet",
+			},
+		},
+		{
+			record:   records[1],
+			severity: logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+			text:     "WARN",
+			body:     "Readiness probe failed: | 503 | 100% ]]>\nbody: <h1>down</h1>",
+			time:     windowEnd.Add(-5 * time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		r := tt.record
+		if r.SeverityNumber != tt.severity || r.SeverityText != tt.text {
+			t.Errorf("got severity %v %q, want %v %q", r.SeverityNumber, r.SeverityText, tt.severity, tt.text)
+		}
+		if got := r.Body.GetStringValue(); got != tt.body {
+			t.Errorf("got body %q, want %q", got, tt.body)
+		}
+		if got := time.Unix(0, int64(r.TimeUnixNano)); !got.Equal(tt.time) {
+			t.Errorf("got time %v, want %v", got.UTC(), tt.time)
+		}
+		if got := time.Unix(0, int64(r.ObservedTimeUnixNano)); !got.Equal(now) {
+			t.Errorf("got observed time %v, want %v", got.UTC(), now)
+		}
+		if tt.attrs != nil && !equalValues(attributeMap(r.Attributes), tt.attrs) {
+			t.Errorf("got attributes %v, want %v", attributeMap(r.Attributes), tt.attrs)
+		}
+	}
+
+	normal := request.ResourceLogs[2].ScopeLogs[0].LogRecords[0]
+	if normal.SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_INFO || normal.SeverityText != "INFO" {
+		t.Errorf("got severity %v %q for a normal event", normal.SeverityNumber, normal.SeverityText)
+	}
+}
+
+func equalValues(got, want map[string]interface{}) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for key, value := range want {
+		if got[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOTLPLogsProtobuf(t *testing.T) {
+	now := windowEnd.Add(time.Second)
+	var request collogspb.ExportLogsServiceRequest
+	if err := proto.Unmarshal(OTLPLogsProtobuf(testSummary(), now), &request); err != nil {
+		t.Fatal(err)
+	}
+	checkOTLPRequest(t, &request, now)
+}
+
+func TestOTLPLogsJSON(t *testing.T) {
+	now := windowEnd.Add(time.Second)
+	data, err := OTLPLogsJSON(testSummary(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request collogspb.ExportLogsServiceRequest
+	if err := protojson.Unmarshal(data, &request); err != nil {
+		t.Fatal(err)
+	}
+	checkOTLPRequest(t, &request, now)
+
+	// Both encodings carry the same request
+	var decoded collogspb.ExportLogsServiceRequest
+	if err := proto.Unmarshal(OTLPLogsProtobuf(testSummary(), now), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(&request, &decoded) {
+		t.Errorf("JSON and protobuf requests differ:\n%v\n%v", &request, &decoded)
+	}
+}
+
+func TestOTLPEmpty(t *testing.T) {
+	summary := testSummary()
+	summary.Groups = nil
+	data, err := OTLPLogsJSON(summary, windowEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != `{"resourceLogs":[]}` {
+		t.Errorf("got %s", got)
+	}
+	if got := OTLPLogsProtobuf(summary, windowEnd); len(got) != 0 {
+		t.Errorf("got %d bytes, want an empty request", len(got))
+	}
+}
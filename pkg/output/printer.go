@@ -0,0 +1,74 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/printers"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// PrinterFormatter renders the summary document with a cli-runtime printer
+// (json, yaml, go-template and jsonpath)
+type PrinterFormatter struct {
+	out     io.Writer
+	printer printers.ResourcePrinter
+}
+
+func (f *PrinterFormatter) Format(summary *types.Summary) error {
+	obj, err := toUnstructured(summary)
+	if err != nil {
+		return err
+	}
+	return f.printer.PrintObj(obj, f.out)
+}
+
+// newTemplatePrinter creates a go-template or jsonpath printer from an
+// inline template or a template file
+func newTemplatePrinter(format, arg string) (printers.ResourcePrinter, error) {
+	if arg == "" {
+		return nil, fmt.Errorf("%s format specified but no template given", format)
+	}
+
+	template := arg
+	if format == "go-template-file" || format == "jsonpath-file" {
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template %s: %v", arg, err)
+		}
+		template = string(data)
+	}
+
+	switch format {
+	case "go-template", "go-template-file":
+		printer, err := printers.NewGoTemplatePrinter([]byte(template))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template %s: %v", template, err)
+		}
+		return printer, nil
+	default:
+		printer, err := printers.NewJSONPathPrinter(template)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing jsonpath %s: %v", template, err)
+		}
+		return printer, nil
+	}
+}
+
+// toUnstructured converts the summary or diff document to the generic form
+// the cli-runtime printers operate on
+func toUnstructured(doc interface{}) (*unstructured.Unstructured, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %v", err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &obj.Object); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %v", err)
+	}
+	return obj, nil
+}
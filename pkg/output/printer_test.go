@@ -0,0 +1,44 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrinterGolden(t *testing.T) {
+	templateFile := filepath.Join(t.TempDir(), "groups.tmpl")
+	template := `{{range .groups}}{{.key}}: {{.total}} ({{.warnings}} warnings){{"\n"}}{{end}}`
+	if err := os.WriteFile(templateFile, []byte(template), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{name: "printer.json.golden", format: "json"},
+		{name: "printer.yaml.golden", format: "yaml"},
+		{name: "printer-go-template.golden", format: "go-template=" + template},
+		{name: "printer-go-template-file.golden", format: "go-template-file=" + templateFile},
+		{name: "printer-jsonpath.golden", format: `jsonpath={range .groups[*].events[*]}{.involvedObject.name}{"\t"}{.reason}{"\t"}{.message}{"\n"}{end}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, tt.format, Options{}, testSummary())
+		})
+	}
+}
+
+func TestPrinterInvalidTemplates(t *testing.T) {
+	for _, format := range []string{
+		"go-template=",
+		"go-template={{.groups",
+		"jsonpath={.groups[",
+		"go-template-file=" + filepath.Join(t.TempDir(), "missing.tmpl"),
+	} {
+		if _, err := NewFormatter(format, nil, Options{}); err == nil {
+			t.Errorf("%q: expected an error", format)
+		}
+	}
+}
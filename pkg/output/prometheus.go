@@ -0,0 +1,175 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// MetricPrefix prefixes the names of all exported metrics
+const MetricPrefix = "kube_event_summary_"
+
+// Content types of the Prometheus text exposition and OpenMetrics formats
+const (
+	PrometheusContentType  = "text/plain; version=0.0.4; charset=utf-8"
+	OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// PrometheusFormatter writes the summary in the Prometheus text exposition
+// format, e.g. for node_exporter's textfile collector or a Pushgateway, or
+// in the OpenMetrics text format
+type PrometheusFormatter struct {
+	out         io.Writer
+	openMetrics bool
+}
+
+func (f *PrometheusFormatter) Format(summary *types.Summary) error {
+	if f.openMetrics {
+		return WriteOpenMetrics(f.out, summary)
+	}
+	return WritePrometheus(f.out, summary)
+}
+
+// metricSample is a single labeled sample of a metric
+type metricSample struct {
+	labels string
+	value  float64
+}
+
+// WritePrometheus writes the summary as metrics in the Prometheus text
+// exposition format
+func WritePrometheus(out io.Writer, summary *types.Summary) error {
+	return writeMetrics(out, summary, false)
+}
+
+// WriteOpenMetrics writes the same metrics as WritePrometheus in the
+// OpenMetrics text format, which ends with an # EOF line
+func WriteOpenMetrics(out io.Writer, summary *types.Summary) error {
+	return writeMetrics(out, summary, true)
+}
+
+func writeMetrics(out io.Writer, summary *types.Summary, openMetrics bool) error {
+	w := bufio.NewWriter(out)
+	multiCluster := len(summary.Clusters) > 0
+
+	// Events by namespace, kind, reason, type and severity
+	events := make(map[string]float64)
+	occurrences := make(map[string]float64)
+	for _, group := range summary.Groups {
+		for i, event := range group.Events {
+			labels := []string{
+				"namespace", event.InvolvedObject.Namespace,
+				"kind", event.InvolvedObject.Kind,
+				"reason", event.Reason,
+				"type", event.Type,
+				"severity", string(types.EventSeverity(event)),
+			}
+			if multiCluster {
+				labels = append([]string{"cluster", group.EventCluster(i)}, labels...)
+			}
+			key := formatLabels(labels...)
+			events[key]++
+			count := event.Count
+			if count < 1 {
+				count = 1
+			}
+			occurrences[key] += float64(count)
+		}
+	}
+	writeMetric(w, "events_total", "gauge",
+		"Number of events in the summary window by namespace, kind, reason, type and severity.", sortedSamples(events))
+	writeMetric(w, "event_occurrences_total", "gauge",
+		"Number of occurrences (sum of event series counts) in the summary window.", sortedSamples(occurrences))
+
+	// Per-group statistics, labeled with the group key dimensions
+	levels := summary.GroupBy
+	var groupTotals, groupWarnings, groupErrors []metricSample
+	for _, group := range summary.Groups {
+		var labels []string
+		if len(levels) > 0 {
+			for i, value := range SplitGroupKey(group.Key, levels) {
+				labels = append(labels, levels[i], value)
+			}
+		} else {
+			labels = []string{"group", group.Key}
+		}
+		key := formatLabels(labels...)
+		groupTotals = append(groupTotals, metricSample{key, float64(group.Total)})
+		groupWarnings = append(groupWarnings, metricSample{key, float64(group.Warnings)})
+		groupErrors = append(groupErrors, metricSample{key, float64(group.Errors)})
+	}
+	writeMetric(w, "group_events", "gauge", "Number of events in each group.", groupTotals)
+	writeMetric(w, "group_warnings", "gauge", "Number of warning events in each group.", groupWarnings)
+	writeMetric(w, "group_errors", "gauge", "Number of error events in each group.", groupErrors)
+
+	// Totals before and after filtering
+	writeMetric(w, "fetched_events", "gauge", "Number of events fetched before filtering, by severity.", []metricSample{
+		{formatLabels("severity", "all"), float64(summary.Totals.Total)},
+		{formatLabels("severity", "warning"), float64(summary.Totals.Warnings)},
+		{formatLabels("severity", "error"), float64(summary.Totals.Errors)},
+	})
+	writeMetric(w, "filtered_events", "gauge", "Number of events left after filtering, by severity.", []metricSample{
+		{formatLabels("severity", "all"), float64(summary.Filtered.Total)},
+		{formatLabels("severity", "warning"), float64(summary.Filtered.Warnings)},
+		{formatLabels("severity", "error"), float64(summary.Filtered.Errors)},
+	})
+
+	if multiCluster {
+		var up []metricSample
+		for _, cluster := range summary.Clusters {
+			value := 1.0
+			if cluster.Error != "" {
+				value = 0
+			}
+			up = append(up, metricSample{formatLabels("cluster", cluster.Name), value})
+		}
+		writeMetric(w, "cluster_up", "gauge", "Whether events could be fetched from the cluster.", up)
+	}
+
+	// Window bounds
+	writeMetric(w, "window_start_seconds", "gauge", "Start of the summary window as a Unix timestamp.",
+		[]metricSample{{"", float64(summary.Since.Unix())}})
+	writeMetric(w, "window_end_seconds", "gauge", "End of the summary window as a Unix timestamp.",
+		[]metricSample{{"", float64(summary.Until.Unix())}})
+
+	if openMetrics {
+		fmt.Fprintln(w, "# EOF")
+	}
+	return w.Flush()
+}
+
+func writeMetric(w io.Writer, name, metricType, help string, samples []metricSample) {
+	name = MetricPrefix + name
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+	for _, sample := range samples {
+		fmt.Fprintf(w, "%s%s %g\n", name, sample.labels, sample.value)
+	}
+}
+
+func sortedSamples(values map[string]float64) []metricSample {
+	samples := make([]metricSample, 0, len(values))
+	for labels, value := range values {
+		samples = append(samples, metricSample{labels, value})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].labels < samples[j].labels })
+	return samples
+}
+
+// formatLabels formats alternating label names and values as {name="value",...}
+func formatLabels(pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	var parts []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
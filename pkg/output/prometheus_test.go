@@ -0,0 +1,125 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+func TestPrometheusGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		multi  bool
+	}{
+		{name: "metrics.prom.golden", format: "prometheus"},
+		{name: "metrics-clusters.prom.golden", format: "prometheus", multi: true},
+		{name: "metrics.openmetrics.golden", format: "openmetrics"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := testSummary()
+			if tt.multi {
+				summary = multiClusterSummary()
+				summary.Groups = inCluster(summary.Groups, "prod")
+			}
+			formatGolden(t, tt.name, tt.format, Options{}, summary)
+		})
+	}
+}
+
+// inCluster returns the groups with their events fetched from cluster
+func inCluster(groups []types.Group, cluster string) []types.Group {
+	var fetched []types.Group
+	for _, group := range groups {
+		summary := &types.GroupSummary{
+			Total:    group.Total,
+			Warnings: group.Warnings,
+			Errors:   group.Errors,
+			Types:    group.Types,
+			Reasons:  group.Reasons,
+		}
+		for _, event := range group.Events {
+			summary.AddEvent(event, cluster)
+		}
+		fetched = append(fetched, types.Group{Key: group.Key, GroupSummary: summary})
+	}
+	return fetched
+}
+
+func TestOpenMetricsMatchesPrometheus(t *testing.T) {
+	var prometheus, openMetrics bytes.Buffer
+	if err := WritePrometheus(&prometheus, testSummary()); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteOpenMetrics(&openMetrics, testSummary()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := openMetrics.String(), prometheus.String()+"# EOF\n"; got != want {
+		t.Errorf("OpenMetrics output is not the Prometheus output ending with # EOF:\n%s", got)
+	}
+}
+
+func TestPrometheusLabelCardinality(t *testing.T) {
+	// A crash-looping deployment with many pods, each with its own messages
+	var events []corev1.Event
+	for i := 0; i < 50; i++ {
+		pod := fmt.Sprintf("web-%d", i)
+		events = append(events,
+			testEvent("shop", "Pod", pod, "Warning", "BackOff", "Back-off restarting "+pod, 1, time.Minute),
+			testEvent("shop", "Pod", pod, "Normal", "Pulled", "Pulled image for "+pod, 1, time.Minute))
+	}
+	summary := testSummary()
+	summary.Groups = append(summary.Groups[:0], testGroup("namespace=shop", events...))
+	summary.GroupBy = []string{"namespace"}
+
+	var out bytes.Buffer
+	if err := WritePrometheus(&out, summary); err != nil {
+		t.Fatal(err)
+	}
+
+	series := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, _, _ := strings.Cut(line, "{")
+		series[name] = append(series[name], line)
+		// Object names and messages never become label values
+		if strings.Contains(line, "web-") {
+			t.Errorf("series labeled with an object: %s", line)
+		}
+	}
+	want := []string{
+		`kube_event_summary_events_total{namespace="shop",kind="Pod",reason="BackOff",type="Warning",severity="error"} 50`,
+		`kube_event_summary_events_total{namespace="shop",kind="Pod",reason="Pulled",type="Normal",severity="normal"} 50`,
+	}
+	if got := series[MetricPrefix+"events_total"]; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got events series:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := series[MetricPrefix+"group_events"]; len(got) != 1 || got[0] != `kube_event_summary_group_events{namespace="shop"} 100` {
+		t.Errorf("got group series %q", got)
+	}
+}
+
+func TestPrometheusLabelEscaping(t *testing.T) {
+	summary := testSummary()
+	summary.GroupBy = []string{"reason"}
+	summary.Groups = summary.Groups[:1]
+	summary.Groups[0].Key = "reason=Back\\Off \"x\"\nnext"
+
+	var out bytes.Buffer
+	if err := WritePrometheus(&out, summary); err != nil {
+		t.Fatal(err)
+	}
+	want := `kube_event_summary_group_events{reason="Back\\Off \"x\"\nnext"} 2` + "\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("metrics lack %q:\n%s", want, out.String())
+	}
+}
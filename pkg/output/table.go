@@ -0,0 +1,203 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+const (
+	// columnPadding is the space tabwriter puts between columns
+	columnPadding = 3
+	// minMessageWidth keeps messages readable on very narrow terminals
+	minMessageWidth = 20
+)
+
+// TableFormatter prints events as an aligned table, one row per event, or
+// one row per group in compact mode
+type TableFormatter struct {
+	out  io.Writer
+	opts Options
+}
+
+func (f *TableFormatter) Format(summary *types.Summary) error {
+	if f.opts.Compact {
+		return f.formatGroups(summary)
+	}
+
+	// Only show the group column when events are actually grouped
+	grouped := f.opts.GroupBy != ""
+
+	headers := []string{"LAST SEEN", "TYPE", "REASON", "OBJECT", "COUNT", "MESSAGE"}
+	if grouped {
+		headers = append([]string{"GROUP"}, headers...)
+	}
+
+	var rows [][]string
+	// Ages are relative to the end of the window, so replayed snapshots
+	// show them as they were when recorded
+	now := summary.Until
+	for _, group := range summary.Groups {
+		for _, event := range group.Events {
+			row := []string{
+				lastSeen(event, now),
+				event.Type,
+				event.Reason,
+				strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+				fmt.Sprint(event.Count),
+				SingleLine(event.Message),
+			}
+			if grouped {
+				row = append([]string{group.Key}, row...)
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	truncateLastColumn(headers, rows, f.opts.Width)
+	return writeTable(f.out, headers, rows, f.opts.NoHeaders)
+}
+
+// formatGroups prints one row per group with its statistics
+func (f *TableFormatter) formatGroups(summary *types.Summary) error {
+	headers := []string{"GROUP", "TOTAL", "WARNINGS", "ERRORS", "TOP REASONS"}
+	var rows [][]string
+	for _, group := range summary.Groups {
+		rows = append(rows, []string{
+			group.Key,
+			fmt.Sprint(group.Total),
+			fmt.Sprint(group.Warnings),
+			fmt.Sprint(group.Errors),
+			formatCounts(topReasons(group.Reasons, 3)),
+		})
+	}
+	truncateLastColumn(headers, rows, f.opts.Width)
+	return writeTable(f.out, headers, rows, f.opts.NoHeaders)
+}
+
+// writeTable writes the rows aligned in columns
+func writeTable(out io.Writer, headers []string, rows [][]string, noHeaders bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, columnPadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// truncateLastColumn shortens the last column so rows fit within width
+func truncateLastColumn(headers []string, rows [][]string, width int) {
+	if width <= 0 || len(headers) == 0 {
+		return
+	}
+
+	last := len(headers) - 1
+	used := 0
+	for i := 0; i < last; i++ {
+		columnWidth := len(headers[i])
+		for _, row := range rows {
+			if len(row[i]) > columnWidth {
+				columnWidth = len(row[i])
+			}
+		}
+		used += columnWidth + columnPadding
+	}
+
+	available := width - used
+	if available < minMessageWidth {
+		available = minMessageWidth
+	}
+	for _, row := range rows {
+		row[last] = truncate(row[last], available)
+	}
+}
+
+// truncate shortens s to at most width characters, marking the cut
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// SingleLine collapses multi-line messages so they fit in a table row or
+// a chat message line
+func SingleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// lastSeen returns how long ago the event was last seen, like kubectl get events
+func lastSeen(event corev1.Event, now time.Time) string {
+	t := types.EventTime(event)
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(t))
+}
+
+// reasonCount is a reason and the number of events with it
+type reasonCount struct {
+	Reason string
+	Count  int
+}
+
+// topReasons returns the n most frequent reasons, most frequent first
+func topReasons(reasons map[string]int, n int) []reasonCount {
+	var counts []reasonCount
+	for reason, count := range reasons {
+		counts = append(counts, reasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Reason < counts[j].Reason
+	})
+	if n > 0 && len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+func formatCounts(counts []reasonCount) string {
+	var parts []string
+	for _, c := range counts {
+		parts = append(parts, fmt.Sprintf("%s=%d", c.Reason, c.Count))
+	}
+	return strings.Join(parts, ",")
+}
+
+// FormatTopReasons formats the n most frequent reasons, e.g. "BackOff=3,Failed=1"
+func FormatTopReasons(reasons map[string]int, n int) string {
+	return formatCounts(topReasons(reasons, n))
+}
+
+// TerminalWidth returns the width of the terminal out writes to, or 0 when
+// out is not a terminal
+func TerminalWidth(out io.Writer) int {
+	f, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
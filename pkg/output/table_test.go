@@ -0,0 +1,47 @@
+package output
+
+import "testing"
+
+func TestTableGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "table.golden", opts: Options{GroupBy: "namespace,reason"}},
+		{name: "table-compact.golden", opts: Options{GroupBy: "namespace,reason", Compact: true}},
+		{name: "table-ungrouped.golden"},
+		{name: "table-no-headers.golden", opts: Options{GroupBy: "namespace,reason", NoHeaders: true}},
+		// Messages are cut to fit the width, but not below a readable minimum
+		{name: "table-narrow.golden", opts: Options{GroupBy: "namespace,reason", Width: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, "table", tt.opts, testSummary())
+		})
+	}
+}
+
+func TestCustomColumnsGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		opts Options
+	}{
+		{name: "custom-columns.golden", spec: "OBJECT:.involvedObject.name,REASON:reason,COUNT:{.count},MESSAGE:.message"},
+		// Missing fields are left blank
+		{name: "custom-columns-missing.golden", spec: "NAME:.metadata.name,ACTION:.action", opts: Options{NoHeaders: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatGolden(t, tt.name, "custom-columns="+tt.spec, tt.opts, testSummary())
+		})
+	}
+}
+
+func TestCustomColumnsInvalid(t *testing.T) {
+	for _, spec := range []string{"", "NAME", "NAME:", ":.reason", "NAME:{.reason", "NAME:.reason[", "NAME:.reason,"} {
+		if _, err := NewFormatter("custom-columns="+spec, nil, Options{}); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
@@ -4,7 +4,7 @@ import (
     "fmt"
     "io"
     
-    "github.com/nareshku/kubectl-event-summary/pkg/events"
+    "github.com/nareshku/kubectl-event-summary/pkg/types"
 )
 
 type WideFormatter struct {
@@ -12,12 +12,12 @@ type WideFormatter struct {
     compact bool
 }
 
-func (f *WideFormatter) Format(groups map[string]*events.GroupSummary, keys []string) error {
-    for _, key := range keys {
-        summary := groups[key]
+func (f *WideFormatter) Format(s *types.Summary) error {
+    for _, group := range s.Groups {
+        summary := group.GroupSummary
         
         // Print group header with summary
-        fmt.Fprintf(f.out, "\n=== %s ===\n", key)
+        fmt.Fprintf(f.out, "\n=== %s ===\n", group.Key)
         fmt.Fprintf(f.out, "Total Events: %d (Warnings: %d)\n", summary.Total, summary.Warnings)
         
         // Print type distribution
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/events"
+	"github.com/nareshku/kubectl-event-summary/pkg/output"
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// ServeOptions contains the options for the serve command
+type ServeOptions struct {
+	// Summary holds the default window, grouping and filters; /summary
+	// requests may override the window and grouping
+	Summary *events.EventSummaryOptions
+	Listen  string
+
+	// aggregate keeps the summary with the defaults up to date as the
+	// informer adds, updates and deletes events
+	aggregate *events.Aggregator
+	lister    corelisters.EventLister
+	synced    cache.InformerSynced
+
+	genericclioptions.IOStreams
+}
+
+// NewServeOptions returns initialized ServeOptions
+func NewServeOptions(streams genericclioptions.IOStreams) *ServeOptions {
+	return &ServeOptions{
+		Summary:   events.NewEventSummaryOptions(streams),
+		IOStreams: streams,
+	}
+}
+
+// Complete completes all the required options
+func (o *ServeOptions) Complete(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the provided options
+func (o *ServeOptions) Validate() error {
+	if o.Listen == "" {
+		return fmt.Errorf("--listen must not be empty")
+	}
+	return o.Summary.ValidateSummary()
+}
+
+// Run starts an informer on events and serves the summary until interrupted
+func (o *ServeOptions) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	config, err := o.Summary.ConfigFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get client config: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %v", err)
+	}
+
+	var namespace string
+	if !o.Summary.AllNs {
+		namespace, _, err = o.Summary.ConfigFlags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return fmt.Errorf("failed to get namespace: %v", err)
+		}
+	}
+
+	if err := o.Summary.OpenStore(); err != nil {
+		return err
+	}
+
+	if err := o.Start(ctx, clientset, namespace); err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              o.Listen,
+		Handler:           o.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(o.ErrOut, "Serving event summary on %s\n", o.Listen)
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve: %v", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// Start starts an informer on the events of the namespace, or of all
+// namespaces if empty, that feeds the aggregate and the local store
+func (o *ServeOptions) Start(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	aggregate, err := o.Summary.NewAggregator()
+	if err != nil {
+		return err
+	}
+	if err := aggregate.AddStored(); err != nil {
+		return err
+	}
+	o.aggregate = aggregate
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(namespace))
+	informer := factory.Core().V1().Events()
+	o.lister = informer.Lister()
+	o.synced = informer.Informer().HasSynced
+	if _, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    o.addEvent,
+		UpdateFunc: func(_, obj interface{}) { o.addEvent(obj) },
+		DeleteFunc: o.deleteEvent,
+	}); err != nil {
+		return fmt.Errorf("failed to watch events: %v", err)
+	}
+	factory.Start(ctx.Done())
+	return nil
+}
+
+// addEvent aggregates an event added or updated by the informer and appends
+// it to the local store
+func (o *ServeOptions) addEvent(obj interface{}) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return
+	}
+	o.aggregate.Add(*event)
+	if err := o.Summary.StoreEvents("", []corev1.Event{*event}); err != nil {
+		fmt.Fprintf(o.ErrOut, "Warning: failed to store event: %v\n", err)
+	}
+}
+
+// deleteEvent removes an event deleted by the informer from the aggregate
+func (o *ServeOptions) deleteEvent(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if event, ok := obj.(*corev1.Event); ok {
+		o.aggregate.Delete(*event)
+	}
+}
+
+// Handler returns the HTTP handler serving /metrics, /healthz and /summary
+func (o *ServeOptions) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", o.serveHealthz)
+	mux.HandleFunc("/metrics", o.serveMetrics)
+	mux.HandleFunc("/summary", o.serveSummary)
+	return mux
+}
+
+func (o *ServeOptions) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	if !o.synced() {
+		http.Error(w, "event informer not synced", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func (o *ServeOptions) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	summary := o.aggregate.Summary()
+	write, contentType := output.WritePrometheus, output.PrometheusContentType
+	// Scrapers that accept OpenMetrics, as Prometheus does, get it instead
+	if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		write, contentType = output.WriteOpenMetrics, output.OpenMetricsContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	if err := write(w, summary); err != nil {
+		fmt.Fprintf(o.ErrOut, "Warning: failed to write metrics: %v\n", err)
+	}
+}
+
+// serveSummary returns the summary document as JSON. The since, groupBy,
+// severity and search query parameters override the defaults, and
+// compact=true leaves out the events. Summaries with the defaults come from
+// the aggregate; overrides summarize the cached events on request.
+func (o *ServeOptions) serveSummary(w http.ResponseWriter, r *http.Request) {
+	opts := *o.Summary
+	query := r.URL.Query()
+	overridden := query.Has("since") || query.Has("groupBy") || query.Has("severity") || query.Has("search")
+	if since := query.Get("since"); since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since: %v", err), http.StatusBadRequest)
+			return
+		}
+		opts.Since = d
+	}
+	if query.Has("groupBy") {
+		opts.GroupBy = query.Get("groupBy")
+	}
+	if severity := query.Get("severity"); severity != "" {
+		opts.Severity = types.Severity(strings.ToLower(severity))
+	}
+	if search := query.Get("search"); search != "" {
+		opts.Search = search
+	}
+	if err := opts.ValidateSummary(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var summary *types.Summary
+	if overridden {
+		var err error
+		if summary, err = o.summarize(opts); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		summary = o.aggregate.Summary()
+	}
+	if query.Get("compact") == "true" {
+		for _, group := range summary.Groups {
+			group.Events = nil
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		fmt.Fprintf(o.ErrOut, "Warning: failed to write summary: %v\n", err)
+	}
+}
+
+// summarize summarizes the events currently held by the informer with opts
+func (o *ServeOptions) summarize(opts events.EventSummaryOptions) (*types.Summary, error) {
+	cached, err := o.lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached events: %v", err)
+	}
+	eventList := make([]corev1.Event, 0, len(cached))
+	for _, event := range cached {
+		eventList = append(eventList, *event)
+	}
+	return opts.Summarize(eventList, time.Now())
+}
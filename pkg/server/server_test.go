@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// testEvent returns an event of a pod in the shop namespace, seen a minute ago
+func testEvent(name, eventType, reason string) *corev1.Event {
+	last := time.Now().Add(-time.Minute)
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-0"},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " for web-0",
+		FirstTimestamp: metav1.NewTime(last),
+		LastTimestamp:  metav1.NewTime(last),
+		Count:          2,
+	}
+}
+
+// startServer serves the events of the fake clientset with the serve
+// defaults
+func startServer(t *testing.T, clientset *fake.Clientset) *httptest.Server {
+	t.Helper()
+	o := NewServeOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Summary.Since = 15 * time.Minute
+	o.Summary.GroupBy = "namespace,reason"
+	o.Summary.Severity = types.SeverityAll
+	o.Listen = ":0"
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := o.Start(ctx, clientset, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), o.synced) {
+		t.Fatal("informer did not sync")
+	}
+
+	server := httptest.NewServer(o.Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+// eventually polls the metrics until they contain line, or not if absent
+func eventually(t *testing.T, url, line string, absent bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, body := get(t, url+"/metrics")
+		if strings.Contains(body, line+"\n") != absent {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("metrics never had %q (absent=%v):\n%s", line, absent, body)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		testEvent("backoff", "Warning", "BackOff"),
+		testEvent("pulled", "Normal", "Pulled"),
+	)
+	server := startServer(t, clientset)
+
+	status, body := get(t, server.URL+"/metrics")
+	if status != http.StatusOK {
+		t.Fatalf("got status %d: %s", status, body)
+	}
+	for _, line := range []string{
+		"# TYPE kube_event_summary_events_total gauge",
+		`kube_event_summary_events_total{namespace="shop",kind="Pod",reason="BackOff",type="Warning",severity="error"} 1`,
+		`kube_event_summary_events_total{namespace="shop",kind="Pod",reason="Pulled",type="Normal",severity="normal"} 1`,
+		`kube_event_summary_event_occurrences_total{namespace="shop",kind="Pod",reason="BackOff",type="Warning",severity="error"} 2`,
+		`kube_event_summary_group_events{namespace="shop",reason="BackOff"} 1`,
+		`kube_event_summary_group_errors{namespace="shop",reason="BackOff"} 1`,
+		`kube_event_summary_fetched_events{severity="all"} 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("metrics lack %q:\n%s", line, body)
+		}
+	}
+
+	// Events added, updated and deleted by the informer are aggregated
+	events := clientset.CoreV1().Events("shop")
+	unhealthy := testEvent("unhealthy", "Warning", "Unhealthy")
+	if _, err := events.Create(context.Background(), unhealthy, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, server.URL, `kube_event_summary_group_warnings{namespace="shop",reason="Unhealthy"} 1`, false)
+
+	unhealthy.Count = 5
+	if _, err := events.Update(context.Background(), unhealthy, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, server.URL, `kube_event_summary_event_occurrences_total{namespace="shop",kind="Pod",reason="Unhealthy",type="Warning",severity="warning"} 5`, false)
+
+	if err := events.Delete(context.Background(), "backoff", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, server.URL, `kube_event_summary_group_events{namespace="shop",reason="BackOff"} 1`, true)
+	eventually(t, server.URL, `kube_event_summary_fetched_events{severity="all"} 2`, false)
+}
+
+func TestMetricsOpenMetrics(t *testing.T) {
+	server := startServer(t, fake.NewSimpleClientset(testEvent("backoff", "Warning", "BackOff")))
+
+	tests := []struct {
+		accept      string
+		contentType string
+		eof         bool
+	}{
+		{contentType: "text/plain; version=0.0.4; charset=utf-8"},
+		{accept: "text/plain;version=0.0.4;q=0.5,*/*;q=0.1", contentType: "text/plain; version=0.0.4; charset=utf-8"},
+		// Prometheus' default scrape Accept header
+		{accept: "application/openmetrics-text;version=1.0.0,application/openmetrics-text;version=0.0.1;q=0.75,text/plain;version=0.0.4;q=0.5,*/*;q=0.1",
+			contentType: "application/openmetrics-text; version=1.0.0; charset=utf-8", eof: true},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/metrics", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%q: got content type %q, want %q", tt.accept, got, tt.contentType)
+		}
+		if got := strings.HasSuffix(string(body), "\n# EOF\n"); got != tt.eof {
+			t.Errorf("%q: got # EOF %v, want %v", tt.accept, got, tt.eof)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	server := startServer(t, fake.NewSimpleClientset(
+		testEvent("backoff", "Warning", "BackOff"),
+		testEvent("unhealthy", "Warning", "Unhealthy"),
+		testEvent("pulled", "Normal", "Pulled"),
+	))
+
+	tests := []struct {
+		name   string
+		query  string
+		keys   []string
+		events int
+	}{
+		{name: "defaults", keys: []string{"namespace=shop,reason=BackOff", "namespace=shop,reason=Pulled", "namespace=shop,reason=Unhealthy"}, events: 3},
+		{name: "compact", query: "?compact=true", keys: []string{"namespace=shop,reason=BackOff", "namespace=shop,reason=Pulled", "namespace=shop,reason=Unhealthy"}},
+		{name: "grouped by type", query: "?groupBy=type", keys: []string{"type=Normal", "type=Warning"}, events: 3},
+		{name: "warnings", query: "?severity=Warning&groupBy=reason", keys: []string{"reason=BackOff", "reason=Unhealthy"}, events: 2},
+		{name: "searched", query: "?search=unhealthy&groupBy=reason", keys: []string{"reason=Unhealthy"}, events: 1},
+		{name: "window before the events", query: "?since=30s", keys: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(t, server.URL+"/summary"+tt.query)
+			if status != http.StatusOK {
+				t.Fatalf("got status %d: %s", status, body)
+			}
+			var summary types.Summary
+			if err := json.Unmarshal([]byte(body), &summary); err != nil {
+				t.Fatal(err)
+			}
+			if summary.Kind != types.SummaryKind {
+				t.Errorf("got kind %q", summary.Kind)
+			}
+			keys, events := []string{}, 0
+			for _, group := range summary.Groups {
+				keys = append(keys, group.Key)
+				events += len(group.Events)
+			}
+			if strings.Join(keys, " ") != strings.Join(tt.keys, " ") || events != tt.events {
+				t.Errorf("got groups %v with %d events, want %v with %d", keys, events, tt.keys, tt.events)
+			}
+		})
+	}
+
+	for _, query := range []string{"?since=soon", "?severity=fatal"} {
+		if status, body := get(t, server.URL+"/summary"+query); status != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want 400: %s", query, status, body)
+		}
+	}
+	if status, body := get(t, server.URL+"/healthz"); status != http.StatusOK || body != "ok\n" {
+		t.Errorf("healthz: got %d %q", status, body)
+	}
+}
@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nareshku/kubectl-event-summary/pkg/types"
+)
+
+// compressed reports whether the snapshot at path is gzipped, by extension
+func compressed(path string) bool {
+	return strings.HasSuffix(path, ".gz")
+}
+
+// Write writes the snapshot as JSON to path, gzipped if it ends in .gz
+func Write(path string, snap *types.Snapshot) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %v", err)
+	}
+	defer file.Close()
+
+	var w io.Writer = file
+	var zw *gzip.Writer
+	if compressed(path) {
+		zw = gzip.NewWriter(file)
+		w = zw
+	}
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to write snapshot: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	return nil
+}
+
+// Read reads a snapshot written by Write
+func Read(path string) (*types.Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %v", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if compressed(path) {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %v", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	var snap types.Snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %v", path, err)
+	}
+	if snap.Kind != types.SnapshotKind {
+		return nil, fmt.Errorf("failed to read snapshot %s: kind is %q, not %s", path, snap.Kind, types.SnapshotKind)
+	}
+	return &snap, nil
+}
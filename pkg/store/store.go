@@ -0,0 +1,337 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Segments hold the events appended during one UTC hour, one record per line
+const (
+	segmentPrefix = "events-"
+	segmentSuffix = ".ndjson"
+	segmentLayout = "20060102T15"
+	segmentSpan   = time.Hour
+)
+
+// record is a line of a segment: an event and the kubeconfig context it was
+// fetched from
+type record struct {
+	Cluster string       `json:"cluster"`
+	Event   corev1.Event `json:"event"`
+}
+
+// recordKey identifies a stored event version
+func recordKey(cluster string, event corev1.Event) string {
+	return cluster + "/" + string(event.UID) + "/" + event.ResourceVersion
+}
+
+// Options configures the local event store
+type Options struct {
+	// Dir holds the segment files; the store is disabled when empty
+	Dir string
+	// Retention is how long events are kept
+	Retention time.Duration
+	// MaxSize, if set, caps the size of the store (e.g. 500Mi); the oldest
+	// segments are removed first
+	MaxSize string
+}
+
+// Enabled reports whether a store directory is configured
+func (o Options) Enabled() bool {
+	return o.Dir != ""
+}
+
+// Validate validates the store options
+func (o Options) Validate() error {
+	if !o.Enabled() {
+		return nil
+	}
+	if o.Retention <= 0 {
+		return fmt.Errorf("invalid store-retention: %s, must be positive", o.Retention)
+	}
+	if _, err := o.maxBytes(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o Options) maxBytes() (int64, error) {
+	if o.MaxSize == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(o.MaxSize)
+	if err != nil || q.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid store-max-size: %s, must be a positive size (e.g. 500Mi)", o.MaxSize)
+	}
+	return q.Value(), nil
+}
+
+// Store is an append-only, file-based history of events that outlives the
+// API server's event TTL. Each version of an event, identified by cluster,
+// UID and resourceVersion, is stored once. A store directory should have a single
+// writer.
+type Store struct {
+	dir       string
+	retention time.Duration
+	maxBytes  int64
+
+	mu   sync.Mutex
+	seen map[string]string // event version -> segment holding it
+	// sizes holds the size of each segment and size their total, so that
+	// every append enforces the retention and maximum size without reading
+	// the directory
+	sizes map[string]int64
+	size  int64
+	now   func() time.Time
+}
+
+// Open opens the store in opts.Dir, creating it if needed, and removes the
+// segments past the retention
+func Open(opts Options) (*Store, error) {
+	maxBytes, err := opts.maxBytes()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store: %v", err)
+	}
+
+	s := &Store{
+		dir:       opts.Dir,
+		retention: opts.Retention,
+		maxBytes:  maxBytes,
+		seen:      make(map[string]string),
+		sizes:     make(map[string]int64),
+		now:       time.Now,
+	}
+	segments, err := s.segments()
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range segments {
+		info, err := os.Stat(filepath.Join(s.dir, segment))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read store: %v", err)
+		}
+		s.sizes[segment] = info.Size()
+		s.size += info.Size()
+	}
+	if err := s.prune(s.now()); err != nil {
+		return nil, err
+	}
+
+	for _, segment := range s.held() {
+		err := s.scan(segment, func(line []byte) {
+			var r record
+			if json.Unmarshal(line, &r) == nil {
+				s.seen[recordKey(r.Cluster, r.Event)] = segment
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Append stores the events of the cluster not stored yet and returns how
+// many were added. It then removes the segments past the retention and, if
+// the store outgrew its maximum size, the oldest ones.
+func (s *Store) Append(cluster string, events []corev1.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	segment := segmentName(now)
+	var buf bytes.Buffer
+	var added []string
+	for _, event := range events {
+		key := recordKey(cluster, event)
+		if _, ok := s.seen[key]; ok {
+			continue
+		}
+		data, err := json.Marshal(record{Cluster: cluster, Event: event})
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode event: %v", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+		added = append(added, key)
+	}
+	if len(added) == 0 {
+		return 0, s.prune(now)
+	}
+
+	file, err := os.OpenFile(filepath.Join(s.dir, segment), os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open store segment: %v", err)
+	}
+	defer file.Close()
+	data := buf.Bytes()
+	// Start on a new line if an interrupted write left the last one unfinished
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+	if _, err := file.Write(data); err != nil {
+		return 0, fmt.Errorf("failed to append to store: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to append to store: %v", err)
+	}
+
+	for _, key := range added {
+		s.seen[key] = segment
+	}
+	s.sizes[segment] += int64(len(data))
+	s.size += int64(len(data))
+	return len(added), s.prune(now)
+}
+
+// Query returns the latest stored version of each event of the cluster that
+// may have happened at or after since
+func (s *Store) Query(cluster string, since time.Time) ([]corev1.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	segments, err := s.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]int)
+	var events []corev1.Event
+	for _, segment := range segments {
+		// Events are appended after they happen, so a segment ending before
+		// since can't hold events of the window
+		start, _ := segmentTime(segment)
+		if start.Add(segmentSpan).Before(since) {
+			continue
+		}
+		err := s.scan(segment, func(line []byte) {
+			var r record
+			// Skip lines cut short by an interrupted write
+			if json.Unmarshal(line, &r) != nil || r.Cluster != cluster {
+				return
+			}
+			event := r.Event
+			if i, ok := latest[string(event.UID)]; ok {
+				events[i] = event
+				return
+			}
+			latest[string(event.UID)] = len(events)
+			events = append(events, event)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// prune removes the segments past the retention, then the oldest segments
+// until the store fits in maxBytes
+func (s *Store) prune(now time.Time) error {
+	cutoff := now.Add(-s.retention)
+	for _, segment := range s.held() {
+		start, _ := segmentTime(segment)
+		expired := start.Add(segmentSpan).Before(cutoff)
+		if !expired && (s.maxBytes == 0 || s.size <= s.maxBytes) {
+			break
+		}
+		if err := s.remove(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) remove(segment string) error {
+	if err := os.Remove(filepath.Join(s.dir, segment)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove store segment: %v", err)
+	}
+	for key, held := range s.seen {
+		if held == segment {
+			delete(s.seen, key)
+		}
+	}
+	s.size -= s.sizes[segment]
+	delete(s.sizes, segment)
+	return nil
+}
+
+// held returns the segments the store holds, oldest first
+func (s *Store) held() []string {
+	segments := make([]string, 0, len(s.sizes))
+	for segment := range s.sizes {
+		segments = append(segments, segment)
+	}
+	sort.Strings(segments)
+	return segments
+}
+
+// segments returns the segment files of the store, oldest first
+func (s *Store) segments() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %v", err)
+	}
+	var segments []string
+	for _, entry := range entries {
+		if _, ok := segmentTime(entry.Name()); ok && !entry.IsDir() {
+			segments = append(segments, entry.Name())
+		}
+	}
+	// Segment names sort chronologically
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// scan calls fn with each line of the segment
+func (s *Store) scan(segment string, fn func(line []byte)) error {
+	file, err := os.Open(filepath.Join(s.dir, segment))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read store: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Bytes())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read store segment %s: %v", segment, err)
+	}
+	return nil
+}
+
+func segmentName(t time.Time) string {
+	return segmentPrefix + t.UTC().Format(segmentLayout) + segmentSuffix
+}
+
+// segmentTime returns when the segment started, and whether name is a segment
+func segmentTime(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix)
+	t, err := time.Parse(segmentLayout, stamp)
+	return t, err == nil
+}
@@ -0,0 +1,292 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func testEvent(uid, resourceVersion, message string) corev1.Event {
+	return corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "event-" + uid,
+			Namespace:       "default",
+			UID:             k8stypes.UID(uid),
+			ResourceVersion: resourceVersion,
+		},
+		Reason:  "BackOff",
+		Message: message,
+	}
+}
+
+// openStore opens a store in a temporary directory whose clock is set to
+// *now
+func openStore(t *testing.T, opts Options, now *time.Time) *Store {
+	if opts.Dir == "" {
+		opts.Dir = t.TempDir()
+	}
+	if opts.Retention == 0 {
+		opts.Retention = 7 * 24 * time.Hour
+	}
+	s, err := Open(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func appendEvents(t *testing.T, s *Store, cluster string, want int, events ...corev1.Event) {
+	t.Helper()
+	added, err := s.Append(cluster, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != want {
+		t.Errorf("appended %d events, want %d", added, want)
+	}
+}
+
+func queryMessages(t *testing.T, s *Store, cluster string, since time.Time) []string {
+	t.Helper()
+	events, err := s.Query(cluster, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, event := range events {
+		messages = append(messages, event.Message)
+	}
+	return messages
+}
+
+func segmentNames(t *testing.T, s *Store) []string {
+	t.Helper()
+	segments, err := s.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return segments
+}
+
+func TestAppendDedupe(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	s := openStore(t, Options{Dir: dir}, &now)
+
+	appendEvents(t, s, "prod", 2, testEvent("a", "1", "a1"), testEvent("b", "1", "b1"))
+	// Versions already stored are skipped
+	appendEvents(t, s, "prod", 0, testEvent("a", "1", "a1"), testEvent("b", "1", "b1"))
+	// A new resourceVersion of the same event is a new version
+	appendEvents(t, s, "prod", 1, testEvent("a", "1", "a1"), testEvent("a", "2", "a2"))
+	// The same version fetched from another cluster is stored separately
+	appendEvents(t, s, "staging", 1, testEvent("a", "1", "a1"))
+
+	// Reopening the store remembers the stored versions
+	reopened := openStore(t, Options{Dir: dir}, &now)
+	appendEvents(t, reopened, "prod", 0, testEvent("a", "2", "a2"), testEvent("b", "1", "b1"))
+	appendEvents(t, reopened, "prod", 1, testEvent("b", "2", "b2"))
+
+	// Queries return the latest version of each event
+	want := []string{"a2", "b2"}
+	if got := queryMessages(t, reopened, "prod", now.Add(-time.Hour)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestQueryPerCluster(t *testing.T) {
+	now := time.Now()
+	s := openStore(t, Options{}, &now)
+
+	appendEvents(t, s, "prod", 2, testEvent("a", "1", "prod a"), testEvent("b", "1", "prod b"))
+	// Same UID and resourceVersion, e.g. a restored cluster
+	appendEvents(t, s, "staging", 1, testEvent("a", "1", "staging a"))
+
+	tests := map[string][]string{
+		"prod":    {"prod a", "prod b"},
+		"staging": {"staging a"},
+		"dev":     nil,
+	}
+	for cluster, want := range tests {
+		if got := queryMessages(t, s, cluster, now.Add(-time.Hour)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", cluster, got, want)
+		}
+	}
+}
+
+func TestSegmentRotation(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	now := start.Add(10 * time.Minute)
+	s := openStore(t, Options{}, &now)
+
+	appendEvents(t, s, "prod", 1, testEvent("a", "1", "a1"))
+	now = start.Add(50 * time.Minute)
+	appendEvents(t, s, "prod", 1, testEvent("b", "1", "b1"))
+	now = start.Add(70 * time.Minute)
+	appendEvents(t, s, "prod", 1, testEvent("a", "2", "a2"))
+	now = start.Add(2*time.Hour + 5*time.Minute)
+	appendEvents(t, s, "prod", 1, testEvent("c", "1", "c1"))
+
+	want := []string{
+		segmentName(start),
+		segmentName(start.Add(time.Hour)),
+		segmentName(start.Add(2 * time.Hour)),
+	}
+	if got := segmentNames(t, s); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got segments %v, want %v", got, want)
+	}
+	if want := "events-" + start.Format("20060102T15") + ".ndjson"; segmentNames(t, s)[0] != want {
+		t.Errorf("got segment name %s, want %s", segmentNames(t, s)[0], want)
+	}
+
+	// Segments ending before since are skipped
+	if got, want := queryMessages(t, s, "prod", start), []string{"a2", "b1", "c1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("since the first hour: got %v, want %v", got, want)
+	}
+	if got, want := queryMessages(t, s, "prod", start.Add(90*time.Minute)), []string{"a2", "c1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("since the second hour: got %v, want %v", got, want)
+	}
+}
+
+func TestPruneRetention(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Hour).Add(-5 * time.Hour)
+	now := start
+	s := openStore(t, Options{Retention: 2 * time.Hour}, &now)
+
+	for i, uid := range []string{"a", "b", "c", "d"} {
+		now = start.Add(time.Duration(i) * time.Hour)
+		appendEvents(t, s, "prod", 1, testEvent(uid, "1", uid))
+	}
+	if got := len(segmentNames(t, s)); got != 4 {
+		t.Fatalf("got %d segments, want 4", got)
+	}
+
+	// Appending prunes the segments that ended before the retention; the
+	// segment of the third hour still holds events within it
+	now = start.Add(4*time.Hour + 30*time.Minute)
+	appendEvents(t, s, "prod", 1, testEvent("e", "1", "e"))
+	want := []string{
+		segmentName(start.Add(2 * time.Hour)),
+		segmentName(start.Add(3 * time.Hour)),
+		segmentName(start.Add(4 * time.Hour)),
+	}
+	if got := segmentNames(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("got segments %v, want %v", got, want)
+	}
+	if got, want := queryMessages(t, s, "prod", start), []string{"c", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// Pruned versions are forgotten and stored again when seen again
+	appendEvents(t, s, "prod", 1, testEvent("a", "1", "a"))
+
+	// Opening the store prunes as well
+	reopened, err := Open(Options{Dir: s.dir, Retention: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := segmentNames(t, reopened); len(got) != 1 {
+		t.Errorf("got segments %v after reopening, want only the last", got)
+	}
+}
+
+// storeSize returns the size of the segment files of the store
+func storeSize(t *testing.T, s *Store) int64 {
+	t.Helper()
+	var size int64
+	for _, segment := range segmentNames(t, s) {
+		info, err := os.Stat(filepath.Join(s.dir, segment))
+		if err != nil {
+			t.Fatal(err)
+		}
+		size += info.Size()
+	}
+	return size
+}
+
+func TestPruneMaxSize(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	now := start
+	dir := t.TempDir()
+	s := openStore(t, Options{Dir: dir, MaxSize: "2Ki"}, &now)
+
+	message := strings.Repeat("x", 600)
+	appendEvents(t, s, "prod", 1, testEvent("a", "1", "a"+message))
+	now = start.Add(time.Hour)
+	appendEvents(t, s, "prod", 1, testEvent("b", "1", "b"+message))
+	want := []string{segmentName(start), segmentName(start.Add(time.Hour))}
+	if got := segmentNames(t, s); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got segments %v, want %v", got, want)
+	}
+
+	// The append that outgrows the store removes the oldest segment at once,
+	// even within the current segment
+	appendEvents(t, s, "prod", 1, testEvent("c", "1", "c"+message))
+	want = []string{segmentName(start.Add(time.Hour))}
+	if got := segmentNames(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("got segments %v, want %v", got, want)
+	}
+	if size := storeSize(t, s); size > 2048 || size != s.size {
+		t.Errorf("store holds %d bytes and tracks %d, want at most 2Ki", size, s.size)
+	}
+	// The removed versions are forgotten
+	appendEvents(t, s, "prod", 1, testEvent("a", "1", "a"))
+
+	// Opening the store with a smaller maximum size prunes the oldest
+	// segments, after measuring them
+	now = start.Add(2 * time.Hour)
+	appendEvents(t, s, "prod", 1, testEvent("d", "1", "d"))
+	reopened := openStore(t, Options{Dir: dir, MaxSize: "1Ki"}, &now)
+	if got := segmentNames(t, reopened); len(got) != 1 || got[0] != segmentName(now) {
+		t.Errorf("got segments %v after reopening, want only the last", got)
+	}
+	if size := storeSize(t, reopened); size > 1024 {
+		t.Errorf("store holds %d bytes after reopening, want at most 1Ki", size)
+	}
+}
+
+func TestInterruptedWrite(t *testing.T) {
+	now := time.Now()
+	s := openStore(t, Options{}, &now)
+	appendEvents(t, s, "prod", 1, testEvent("a", "1", "a1"))
+
+	// Leave the last line unfinished, like a crash while appending
+	file, err := os.OpenFile(filepath.Join(s.dir, segmentName(now)), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"cluster":"prod","event":{"metadata":{"uid":"b"`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	appendEvents(t, s, "prod", 1, testEvent("c", "1", "c1"))
+	if got, want := queryMessages(t, s, "prod", now.Add(-time.Hour)), []string{"a1", "c1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		opts    Options
+		wantErr bool
+	}{
+		{Options{}, false},
+		{Options{Dir: "store", Retention: time.Hour}, false},
+		{Options{Dir: "store", Retention: time.Hour, MaxSize: "500Mi"}, false},
+		{Options{Dir: "store"}, true},
+		{Options{Dir: "store", Retention: time.Hour, MaxSize: "lots"}, true},
+		{Options{Dir: "store", Retention: time.Hour, MaxSize: "-1Gi"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: got error %v, want error %v", tt.opts, err, tt.wantErr)
+		}
+	}
+}
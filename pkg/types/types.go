@@ -1,7 +1,12 @@
 package types
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Severity represents the event severity level
@@ -14,17 +19,309 @@ const (
 	SeverityError   Severity = "error"
 )
 
+// IsErrorReason reports whether an event reason looks like an error
+func IsErrorReason(reason string) bool {
+	reason = strings.ToLower(reason)
+	return strings.Contains(reason, "error") ||
+		strings.Contains(reason, "failed") ||
+		strings.Contains(reason, "backoff")
+}
+
+// EventSeverity classifies an event as normal, warning or error. Errors are
+// Warning events whose reason looks like an error.
+func EventSeverity(event corev1.Event) Severity {
+	if event.Type != "Warning" {
+		return SeverityNormal
+	}
+	if IsErrorReason(event.Reason) {
+		return SeverityError
+	}
+	return SeverityWarning
+}
+
+// EventTime returns the time an event was last seen, preferring EventTime,
+// then LastTimestamp, then FirstTimestamp
+func EventTime(event corev1.Event) time.Time {
+	t := event.EventTime.Time
+	if t.IsZero() {
+		t = event.LastTimestamp.Time
+	}
+	if t.IsZero() {
+		t = event.FirstTimestamp.Time
+	}
+	return t
+}
+
 // GroupSummary holds statistics for a group of events
 type GroupSummary struct {
-	Total    int
-	Warnings int
-	Errors   int
-	Types    map[string]int
-	Reasons  map[string]int
-	Events   []corev1.Event
+	Total    int            `json:"total"`
+	Warnings int            `json:"warnings"`
+	Errors   int            `json:"errors"`
+	Types    map[string]int `json:"types"`
+	Reasons  map[string]int `json:"reasons"`
+	Events   []corev1.Event `json:"events,omitempty"`
+	// clusters holds the kubeconfig context each event of Events was
+	// fetched from, kept beside the events so the API objects are unchanged
+	clusters []string
 
 	// Initial totals before filtering
-	InitialTotal    int
-	InitialWarnings int
-	InitialErrors   int
-} 
\ No newline at end of file
+	InitialTotal    int `json:"-"`
+	InitialWarnings int `json:"-"`
+	InitialErrors   int `json:"-"`
+}
+
+// SummaryKind and SummaryAPIVersion identify the summary document
+const (
+	SummaryKind       = "EventSummary"
+	SummaryAPIVersion = "eventsummary.nareshku.github.io/v1alpha1"
+)
+
+// Summary is the document rendered by the output formats
+type Summary struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Totals counts all fetched events before filtering
+	Totals Totals `json:"totals"`
+	// Filtered counts the events left in the groups
+	Filtered Totals `json:"filtered"`
+	// Clusters holds per-cluster totals in multi-cluster mode
+	Clusters []ClusterTotals `json:"clusters,omitempty"`
+
+	// Since and Until bound the time window of the summary
+	Since   time.Time `json:"since"`
+	Until   time.Time `json:"until"`
+	GroupBy []string  `json:"groupBy,omitempty"`
+
+	Groups []Group `json:"groups"`
+
+	// Thresholds holds the outcome of each --fail-on rule
+	Thresholds []ThresholdResult `json:"thresholds,omitempty"`
+
+	// Anomalies ranks the groups whose count deviates most from their
+	// baseline, with --anomalies
+	Anomalies []Anomaly `json:"anomalies,omitempty"`
+
+	// Problems lists the known failure patterns found in the events, with
+	// --problems
+	Problems []Problem `json:"problems,omitempty"`
+}
+
+// Totals holds event counts
+type Totals struct {
+	Total    int `json:"total"`
+	Warnings int `json:"warnings"`
+	Errors   int `json:"errors"`
+}
+
+// ClusterTotals holds the totals of a single cluster, or why it was unreachable
+type ClusterTotals struct {
+	Name   string `json:"name"`
+	Totals `json:",inline"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Group is a GroupSummary with its group key
+type Group struct {
+	Key           string `json:"key"`
+	*GroupSummary `json:",inline"`
+}
+
+// ThresholdResult is the outcome of a --fail-on rule
+type ThresholdResult struct {
+	Rule string `json:"rule"`
+	// Field names the compared value: total, warnings, errors, or count
+	// for reason rules
+	Field    string `json:"field"`
+	Actual   int    `json:"actual"`
+	Breached bool   `json:"breached"`
+}
+
+// String describes the result, e.g. "warnings>0 (warnings=3)"
+func (r ThresholdResult) String() string {
+	return fmt.Sprintf("%s (%s=%d)", r.Rule, r.Field, r.Actual)
+}
+
+// Anomaly compares the count of a group in the summary window with its
+// counts in the baseline windows
+type Anomaly struct {
+	Key     string `json:"key"`
+	Current int    `json:"current"`
+	// BaselineMean and BaselineStdDev describe the counts of the group in
+	// the baseline windows
+	BaselineMean   float64 `json:"baselineMean"`
+	BaselineStdDev float64 `json:"baselineStdDev"`
+	// ZScore is the deviation from the mean in standard deviations, which
+	// are floored at 1 so steady or new groups get finite scores
+	ZScore float64 `json:"zScore"`
+	// Ratio is Current divided by BaselineMean; it is left out for groups
+	// absent from the baseline, which are marked New
+	Ratio float64 `json:"ratio,omitempty"`
+	New   bool    `json:"new,omitempty"`
+}
+
+// Problem is a known failure pattern recognized in the events of a workload
+type Problem struct {
+	// Type names the pattern, e.g. CrashLoopBackOff or ImagePullFailure
+	Type    string `json:"type"`
+	Cluster string `json:"cluster,omitempty"`
+	// Workload is the affected workload, inferred from pod names, or the
+	// involved object itself
+	Workload ObjectReference `json:"workload"`
+	// Objects lists the names of the involved objects
+	Objects []string `json:"objects"`
+	// Count sums the occurrences of the matching events
+	Count     int             `json:"count"`
+	FirstSeen time.Time       `json:"firstSeen"`
+	LastSeen  time.Time       `json:"lastSeen"`
+	Duration  metav1.Duration `json:"duration"`
+	// Message is the latest matching event message
+	Message string `json:"message"`
+	Hint    string `json:"hint"`
+}
+
+// AddEvent appends an event fetched from the given kubeconfig context
+func (g *GroupSummary) AddEvent(event corev1.Event, cluster string) {
+	g.Events = append(g.Events, event)
+	g.clusters = append(g.clusters, cluster)
+}
+
+// EventCluster returns the kubeconfig context the i-th event was fetched
+// from, or "" if unknown
+func (g *GroupSummary) EventCluster(i int) string {
+	if i < len(g.clusters) {
+		return g.clusters[i]
+	}
+	return ""
+}
+
+// FilterEvents returns a copy of the group holding only the events keep
+// returns true for; the counts are unchanged
+func (g *GroupSummary) FilterEvents(keep func(event corev1.Event) bool) *GroupSummary {
+	filtered := *g
+	filtered.Events, filtered.clusters = nil, nil
+	for i, event := range g.Events {
+		if keep(event) {
+			filtered.AddEvent(event, g.EventCluster(i))
+		}
+	}
+	return &filtered
+}
+
+// WithoutEvents returns a copy of the summary whose groups hold no events
+func (s *Summary) WithoutEvents() *Summary {
+	stripped := *s
+	stripped.Groups = make([]Group, 0, len(s.Groups))
+	for _, group := range s.Groups {
+		groupSummary := group.FilterEvents(func(corev1.Event) bool { return false })
+		stripped.Groups = append(stripped.Groups, Group{Key: group.Key, GroupSummary: groupSummary})
+	}
+	return &stripped
+}
+
+// SnapshotKind identifies a recorded snapshot document
+const SnapshotKind = "EventSnapshot"
+
+// Snapshot holds the raw events recorded by the record command, so any
+// summary can later be rendered over them with --from-snapshot
+type Snapshot struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// RecordedAt is when the events were fetched; summaries over the
+	// snapshot use it as the current time
+	RecordedAt   time.Time `json:"recordedAt"`
+	MultiCluster bool      `json:"multiCluster,omitempty"`
+
+	// AllNamespaces, Namespaces, ResourceArgs, Selector and Recursive record
+	// the scope the events were fetched with
+	AllNamespaces bool     `json:"allNamespaces,omitempty"`
+	Namespaces    []string `json:"namespaces,omitempty"`
+	ResourceArgs  []string `json:"resourceArgs,omitempty"`
+	Selector      string   `json:"selector,omitempty"`
+	Recursive     bool     `json:"recursive,omitempty"`
+
+	Clusters []SnapshotCluster `json:"clusters"`
+}
+
+// SnapshotCluster holds the events recorded from a single cluster
+type SnapshotCluster struct {
+	Name string `json:"name"`
+	// Namespace is the namespace events were listed in, empty for all
+	Namespace string `json:"namespace,omitempty"`
+	// Totals counts all listed events before narrowing to the targets
+	Totals    `json:",inline"`
+	Forbidden []string `json:"forbidden,omitempty"`
+	// Targets lists the objects the resource arguments and selector
+	// resolved to, including owned objects with --recursive
+	Targets []ObjectReference `json:"targets,omitempty"`
+	// Owners maps the Pods of problem events to the workloads controlling
+	// them
+	Owners []Owner `json:"owners,omitempty"`
+	Error  string  `json:"error,omitempty"`
+
+	Events []corev1.Event `json:"events"`
+}
+
+// ObjectReference identifies an object by kind, namespace and name
+type ObjectReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// Owner is the workload controlling an object, resolved from its
+// ownerReferences
+type Owner struct {
+	Object   ObjectReference `json:"object"`
+	Workload ObjectReference `json:"workload"`
+}
+
+// DiffKind identifies the document written by the diff command
+const DiffKind = "EventSummaryDiff"
+
+// Diff compares the groups of two summaries
+type Diff struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Before  DiffWindow `json:"before"`
+	After   DiffWindow `json:"after"`
+	GroupBy []string   `json:"groupBy,omitempty"`
+
+	// NewReasons lists the reasons of the after window that never appeared
+	// in the before window
+	NewReasons []string    `json:"newReasons,omitempty"`
+	Groups     []GroupDiff `json:"groups"`
+}
+
+// DiffWindow describes one side of a diff
+type DiffWindow struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// Snapshot is the snapshot file the window was taken from, if any
+	Snapshot string `json:"snapshot,omitempty"`
+	// Totals counts the occurrences of the grouped events in the window
+	Totals `json:",inline"`
+}
+
+// DiffStatus tells how a group changed between the two windows
+type DiffStatus string
+
+const (
+	DiffNew       DiffStatus = "new"
+	DiffRemoved   DiffStatus = "removed"
+	DiffChanged   DiffStatus = "changed"
+	DiffUnchanged DiffStatus = "unchanged"
+)
+
+// GroupDiff holds the occurrences of a group in both windows
+type GroupDiff struct {
+	Key    string     `json:"key"`
+	Status DiffStatus `json:"status"`
+	Before Totals     `json:"before"`
+	After  Totals     `json:"after"`
+	// Delta is After minus Before
+	Delta Totals `json:"delta"`
+	// NewReasons lists the reasons of the group that never appeared in
+	// the before window
+	NewReasons []string `json:"newReasons,omitempty"`
+}